@@ -0,0 +1,8 @@
+module github.com/schuylermartin45/gotris
+
+go 1.16
+
+require (
+	github.com/gdamore/tcell v1.4.0
+	golang.org/x/term v0.0.0-20201126162022-7de9c90e9dd1
+)
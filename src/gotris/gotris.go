@@ -1,3 +1,6 @@
+//go:build !js || !wasm
+// +build !js !wasm
+
 /*
  * File:        gotris.go
  *
@@ -8,11 +11,16 @@
 package main
 
 import (
-	"./model"
-	"./view"
+	"errors"
+	"flag"
 	"fmt"
+	"github.com/schuylermartin45/gotris/src/gotris/model"
+	"github.com/schuylermartin45/gotris/src/gotris/view"
+	"github.com/schuylermartin45/gotris/src/gotris/view/api"
 	"os"
 	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 /***** Constants *****/
@@ -23,56 +31,553 @@ const (
 	TEXT_MODE  string = "text"
 )
 
+// Sub-command that displays the help menu
+const HELP_CMD string = "help"
+
+// Sub-command that plays the game over HTTP, instead of rendering it
+const SERVE_CMD string = "serve"
+
+// Sub-command that times the computer playing a whole game
+const BENCH_CMD string = "bench"
+
+// Help menu of the bench sub-command
+const BENCH_HELP string = "Bench Mode\n" +
+	"\nAbout\n" +
+	"  Times the computer playing a whole game, as fast as it can, to track\n" +
+	"  the performance of the game over time. Games are seeded (with 0 by\n" +
+	"  default), so the same options always play the same game.\n" +
+	"\nThe final score is printed with the timing. Pass it to `--expect` to\n" +
+	"fail when a change makes the computer play differently."
+
 // USAGE message to display on bad input
-const USAGE string = "Usage: gotris [render mode] [help]"
+const USAGE string = "Usage: gotris [render mode] [options]\n" +
+	"       gotris serve [address] [options]\n" +
+	"       gotris bench [options]\n" +
+	"       gotris help [render mode | serve | bench]"
+
+/***** Types *****/
+
+// difficulty is a preset for how fast tiles fall
+type difficulty struct {
+	interval time.Duration
+	floor    time.Duration
+}
+
+// options holds the settings picked on the command line
+type options struct {
+	// Options for any mode
+	zen        bool
+	survival   bool
+	garbage    uint
+	level      uint
+	seed       int64
+	daily      string
+	difficulty string
+	rotation   string
+	lockOnSoft bool
+	instant    bool
+	saveConfig bool
+	// Options for the bench sub-command
+	expect uint
+	// Options for the text mode
+	noPreview  bool
+	hidden     bool
+	colorCycle bool
+	grid       bool
+	heatmap    bool
+	dropPath   bool
+	mouse      bool
+	practice   bool
+	big        bool
+	zone       bool
+	preview    string
+	noEffects  bool
+	glyph      string
+	scheme     string
+	attract    time.Duration
+	noMenu     bool
+}
+
+/***** Variables *****/
+
+// Difficulty presets, by name
+var difficulties = map[string]difficulty{
+	"easy":   {800 * time.Millisecond, 150 * time.Millisecond},
+	"normal": {model.DefaultGravityInterval, model.DefaultGravityFloor},
+	"hard":   {300 * time.Millisecond, 50 * time.Millisecond},
+}
+
+// Rotation systems, by name
+var rotationSystems = map[string]model.RotationSystem{
+	"classic": model.ClassicRotation,
+	"srs":     model.SRS,
+}
+
+// Places the next tile and score can be drawn, by name
+var previewPositions = map[string]view.PreviewPosition{
+	"right": view.PreviewRight,
+	"left":  view.PreviewLeft,
+	"below": view.PreviewBelow,
+}
 
 /***** Functions *****/
 
+/*
+ Constructs the command line flags available in a render mode.
+
+ @param mode Render mode the flags are for.
+ @param opts Settings the flags are parsed into.
+
+ @return The flags of the render mode.
+*/
+func newFlagSet(mode string, opts *options) *flag.FlagSet {
+	flags := flag.NewFlagSet(mode, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), USAGE)
+		fmt.Fprintf(flags.Output(), "\nOptions for the `%s` mode:\n", mode)
+		flags.PrintDefaults()
+	}
+
+	flags.BoolVar(&opts.zen, "zen", false, "Endless game, the stack never tops out")
+	flags.BoolVar(&opts.survival, "survival", false, "Garbage rises from the bottom, faster every level")
+	flags.UintVar(&opts.garbage, "garbage", 0, "Start with `rows` of garbage to dig out of")
+	flags.UintVar(&opts.level, "level", 0, "Level to start at")
+	flags.Int64Var(&opts.seed, "seed", 0, "Seed for picking tiles, to replay the same game (default random)")
+	flags.StringVar(&opts.daily, "daily", "", "Play the game for a shareable `code`, like today's date")
+	flags.StringVar(&opts.difficulty, "difficulty", "normal", "How fast tiles fall: easy, normal, or hard")
+	flags.StringVar(&opts.rotation, "rotation", "classic", "How tiles rotate against walls: classic or srs")
+	flags.BoolVar(&opts.lockOnSoft, "soft-drop-lock", false, "Lock tiles as soon as a soft drop lands them")
+	flags.BoolVar(&opts.instant, "instant-gravity", false, "Drop tiles to the floor as soon as they spawn (20G)")
+	flags.BoolVar(&opts.saveConfig, "save-config", false, "Save the render mode and options as your preferences")
+	if mode == BENCH_CMD {
+		flags.UintVar(&opts.expect, "expect", 0, "Fail unless the game ends with this base `score`")
+	}
+	if mode != TEXT_MODE {
+		return flags
+	}
+	flags.BoolVar(&opts.noPreview, "no-preview", false, "Hide the next tile")
+	flags.StringVar(&opts.preview, "preview", "right", "Where to draw the next tile and score: right, left, or below")
+	flags.BoolVar(&opts.hidden, "hidden", false, "Hide placed blocks")
+	flags.BoolVar(&opts.colorCycle, "color-cycle", false, "Change colors every few levels")
+	flags.BoolVar(&opts.grid, "grid", false, "Draw grid lines on the board")
+	flags.BoolVar(&opts.heatmap, "heatmap", false, "Draw the height of every column beside the board")
+	flags.BoolVar(&opts.dropPath, "drop-path", false, "Draw the path the tile takes on a hard drop")
+	flags.BoolVar(&opts.mouse, "mouse", false, "Click a column to drop the tile in it and scroll to rotate")
+	flags.BoolVar(&opts.practice, "practice", false, "Hint where to put each tile (toggle with h)")
+	flags.BoolVar(&opts.big, "big", false, "Draw blocks at twice the size")
+	flags.BoolVar(&opts.zone, "zone", false, "Press z to enter and exit the zone, banking cleared rows for a bonus")
+	flags.BoolVar(&opts.noEffects, "no-effects", false, "Turn off the terminal bell and flashing")
+	flags.StringVar(&opts.glyph, "glyph", "", "Draw blocks with a different `char`acter")
+	flags.StringVar(&opts.scheme, "scheme", "classic",
+		"Color scheme: "+strings.Join(view.ColorSchemeNames(), ", "))
+	flags.BoolVar(&opts.noMenu, "no-menu", false, "Skip the title screen and start playing right away")
+	flags.DurationVar(&opts.attract, "attract", view.DefaultAttractTimeout,
+		"Idle time on the title screen before a demo game plays, 0 to disable")
+	return flags
+}
+
+/*
+ Checks that the settings picked on the command line make sense.
+
+ @param opts Settings to check.
+
+ @return An error describing the first invalid setting found.
+*/
+func validateOptions(opts options) error {
+	if opts.zen && opts.survival {
+		return errors.New("zen and survival can not be played at once")
+	}
+	if opts.garbage > uint(model.BoardHeight) {
+		return fmt.Errorf("garbage must be at most %d rows", model.BoardHeight)
+	}
+	if (opts.daily != "") && (opts.seed != 0) {
+		return errors.New("seed and daily can not be picked at once")
+	}
+	if opts.level > 255 {
+		return errors.New("level must be at most 255")
+	}
+	if _, ok := difficulties[opts.difficulty]; !ok {
+		return fmt.Errorf("unknown difficulty %q", opts.difficulty)
+	}
+	if _, ok := rotationSystems[opts.rotation]; !ok {
+		return fmt.Errorf("unknown rotation system %q", opts.rotation)
+	}
+	if _, ok := previewPositions[opts.preview]; (opts.preview != "") && !ok {
+		return fmt.Errorf("unknown preview position %q", opts.preview)
+	}
+	if (opts.glyph != "") && (utf8.RuneCountInString(opts.glyph) != 1) {
+		return errors.New("glyph must be a single character")
+	}
+	return nil
+}
+
+/*
+ Displays the help menu. With a render mode, the mode's help menu is displayed
+ instead of the general one.
+
+ @param args    Arguments given to the help sub-command.
+ @param modeMap Look-up table of render modes.
+*/
+func printHelp(args []string, modeMap map[string]view.Display) {
+	if len(args) > 0 {
+		mode := strings.ToLower(args[0])
+		display, ok := modeMap[mode]
+		if ok {
+			fmt.Println(display.RenderHelpMenu())
+		} else if mode == SERVE_CMD {
+			fmt.Println(api.HelpMenu())
+		} else if mode == BENCH_CMD {
+			fmt.Println(BENCH_HELP)
+		} else {
+			fmt.Fprintln(os.Stderr, USAGE)
+			os.Exit(view.ERROR_USAGE)
+		}
+		flags := newFlagSet(mode, new(options))
+		flags.SetOutput(os.Stdout)
+		flags.Usage()
+		return
+	}
+	fmt.Println("Gotris: A Go-implementation of Tetris")
+	fmt.Println("\nAbout")
+	fmt.Println("  Author: Schuyler Martin")
+	fmt.Println("  Date:   January 2020")
+	fmt.Println()
+	fmt.Println(USAGE)
+	fmt.Println("\nRender modes:")
+	fmt.Println("  * `debug`: Basic rendering mode, used for debugging.")
+	fmt.Println("  * `text`: Advanced text rendering mode (default).")
+	fmt.Println("\nSub-commands:")
+	fmt.Println("  * `serve`: Plays the game over HTTP, for other programs to control.")
+	fmt.Println("  * `bench`: Times the computer playing a whole game.")
+	fmt.Println("\nRun `gotris help [render mode]` for the options of a mode.")
+}
+
+/*
+ Constructs the board for a new game.
+
+ @param opts      Settings picked on the command line.
+ @param fixedSeed True to use the seed in the settings. False for a random game.
+
+ @return A new board.
+*/
+func newGameBoard(opts options, fixedSeed bool) *model.Board {
+	board := model.NewBoard()
+	if fixedSeed {
+		board = model.NewBoardWithGarbage(opts.seed, uint8(opts.garbage))
+	}
+	if opts.zen {
+		board.SetGameMode(model.ZenMode{})
+	}
+	if opts.survival {
+		board.SetGameMode(model.NewSurvivalMode())
+	}
+	board.SetStartLevel(uint8(opts.level))
+	board.SetGravity(difficulties[opts.difficulty].interval, difficulties[opts.difficulty].floor)
+	board.SetRotationSystem(rotationSystems[opts.rotation])
+	board.SetSoftDropLock(opts.lockOnSoft)
+	board.SetInstantGravity(opts.instant)
+	return board
+}
+
+/*
+ Get the name of the rules picked for the game.
+
+ @param opts Settings picked.
+
+ @return One of `view.GameModeNames`.
+*/
+func gameModeName(opts options) string {
+	if opts.zen {
+		return "zen"
+	} else if opts.survival {
+		return "survival"
+	}
+	return "classic"
+}
+
+/*
+ Picks the rules to play by.
+
+ @param name One of `view.GameModeNames`.
+ @param opts Settings to update.
+*/
+func setGameMode(name string, opts *options) {
+	opts.zen = name == "zen"
+	opts.survival = name == "survival"
+}
+
+/*
+ Fills in settings from the player's preferences. Settings picked on the command
+ line take priority.
+
+ @param config Player's preferences.
+ @param set    Names of the flags picked on the command line.
+ @param opts   Settings to fill in.
+*/
+func applyConfig(config view.Config, set map[string]bool, opts *options) {
+	if !set["zen"] && !set["survival"] && (config.GameMode != "") {
+		setGameMode(config.GameMode, opts)
+	}
+	if !set["level"] && (config.Level > 0) {
+		opts.level = uint(config.Level)
+	}
+	if !set["scheme"] && (config.Scheme != "") {
+		opts.scheme = config.Scheme
+	}
+	if !set["glyph"] && (config.Glyph != "") {
+		opts.glyph = config.Glyph
+	}
+	if !set["no-effects"] && (config.Effects != nil) {
+		opts.noEffects = !*config.Effects
+	}
+}
+
+/*
+ Records the render mode and settings picked in the player's preferences. Key
+ bindings are kept as they are.
+
+ @param config Preferences to update.
+ @param mode   Render mode being played.
+ @param opts   Settings picked.
+ @param flags  Flags of the render mode. Text mode settings are only recorded in
+               the text mode.
+*/
+func updateConfig(config *view.Config, mode string, opts options, flags *flag.FlagSet) {
+	if (mode != SERVE_CMD) && (mode != BENCH_CMD) {
+		config.Mode = mode
+	}
+	config.GameMode = gameModeName(opts)
+	config.Level = uint8(opts.level)
+	if flags.Lookup("scheme") != nil {
+		config.Scheme = opts.scheme
+		config.Glyph = opts.glyph
+		effects := !opts.noEffects
+		config.Effects = &effects
+	}
+}
+
+/*
+ Has the computer play a game until it ends. Iterations are run back to back,
+ without waiting on gravity.
+
+ @param board Board to play on.
+
+ @return The number of iterations the game lasted.
+*/
+func playComputerGame(board *model.Board) uint64 {
+	iterations := uint64(0)
+	for {
+		iterations++
+		if _, gameDone := board.Next(); gameDone {
+			return iterations
+		}
+		// Place every tile as soon as it spawns
+		if board.HardDropDistance() > 0 {
+			if placement, ok := board.BestPlacement(); ok {
+				board.ApplyPlacement(placement)
+			}
+		}
+	}
+}
+
 /*
  Main entry point of the Gotris project.
 */
 func main() {
 	// Set a default mode and construct a look-up table
 	mode := TEXT_MODE
+	textGame := view.NewTextGame()
 	modeMap := map[string]view.Display{
 		DEBUG_MODE: new(view.DebugGame),
-		TEXT_MODE:  new(view.TextGame),
-	}
-
-	// Handle user input
-	argc := len(os.Args)
-	if argc > 1 {
-		if _, ok := modeMap[os.Args[1]]; ok {
-			mode = os.Args[1]
-		} else if strings.ToLower(os.Args[1]) == "help" {
-			fmt.Println("Gotris: A Go-implementation of Tetris")
-			fmt.Println("\nAbout")
-			fmt.Println("  Author: Schuyler Martin")
-			fmt.Println("  Date:   January 2020")
-			fmt.Println("\n" + USAGE + "\n")
-			fmt.Println("Render modes:")
-			fmt.Println("  * `debug`: Basic rendering mode, used for debugging.")
-			fmt.Println("  * `text`: Advanced text rendering mode.")
+		TEXT_MODE:  textGame,
+	}
+
+	// Load the player's preferences. Players without a configuration directory
+	// just don't get any.
+	var config view.Config
+	configPath, err := view.ConfigPath()
+	if err == nil {
+		config, err = view.LoadConfig(configPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(view.ERROR_USAGE)
+		}
+		if config.Mode != "" {
+			mode = strings.ToLower(config.Mode)
+		}
+	}
+
+	// Handle user input. The render mode (or help) comes first, if given.
+	args := os.Args[1:]
+	if (len(args) > 0) && !strings.HasPrefix(args[0], "-") {
+		mode = strings.ToLower(args[0])
+		args = args[1:]
+	}
+	if mode == HELP_CMD {
+		printHelp(args, modeMap)
+		os.Exit(view.EXIT_SUCCESS)
+	}
+	// The server takes the address to listen on, if given, before its options.
+	addr := api.DefaultAddress
+	if mode == SERVE_CMD {
+		if (len(args) > 0) && !strings.HasPrefix(args[0], "-") {
+			addr = args[0]
+			args = args[1:]
+		}
+	} else if _, ok := modeMap[mode]; !ok && (mode != BENCH_CMD) {
+		fmt.Fprintln(os.Stderr, USAGE)
+		os.Exit(view.ERROR_USAGE)
+	}
+
+	var opts options
+	flags := newFlagSet(mode, &opts)
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
 			os.Exit(view.EXIT_SUCCESS)
-		} else {
-			fmt.Fprintf(os.Stderr, "%v\n", USAGE)
+		}
+		os.Exit(view.ERROR_USAGE)
+	}
+	if flags.NArg() > 0 {
+		flags.Usage()
+		os.Exit(view.ERROR_USAGE)
+	}
+	set := make(map[string]bool)
+	flags.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	applyConfig(config, set, &opts)
+	if err := validateOptions(opts); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flags.Usage()
+		os.Exit(view.ERROR_USAGE)
+	}
+
+	// Every game uses the same seed when one is picked or there is garbage to
+	// dig out of, so playing again retries the same scenario. Benchmarks always
+	// play the same game.
+	fixedSeed := (opts.garbage > 0) || set["seed"] || (opts.daily != "") || (mode == BENCH_CMD)
+	if opts.daily != "" {
+		opts.seed = model.SeedFromString(opts.daily)
+	} else if !fixedSeed {
+		opts.seed = time.Now().UnixNano()
+	}
+
+	if opts.saveConfig {
+		if configPath == "" {
+			fmt.Fprintln(os.Stderr, "no configuration directory to save preferences in")
 			os.Exit(view.ERROR_USAGE)
 		}
-		if argc > 2 {
-			if strings.ToLower(os.Args[2]) == "help" {
-				fmt.Println(modeMap[mode].RenderHelpMenu())
-				os.Exit(view.EXIT_SUCCESS)
-			} else {
-				fmt.Fprintf(os.Stderr, "%v\n", USAGE)
+		updateConfig(&config, mode, opts, flags)
+		if err := view.SaveConfig(configPath, config); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(view.ERROR_USAGE)
+		}
+		fmt.Println("Saved preferences to " + configPath)
+	}
+
+	if mode == SERVE_CMD {
+		server := api.NewServer(func() *model.Board {
+			return newGameBoard(opts, fixedSeed)
+		})
+		fmt.Println("Serving Gotris on " + addr)
+		if err := server.ListenAndServe(addr); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(view.ERROR_SERVE)
+		}
+		os.Exit(view.EXIT_SUCCESS)
+	}
+
+	if mode == BENCH_CMD {
+		// Zen games never end, so there would be nothing to time
+		if opts.zen {
+			fmt.Fprintln(os.Stderr, "zen games can not be benchmarked")
+			os.Exit(view.ERROR_USAGE)
+		}
+		board := newGameBoard(opts, fixedSeed)
+		start := time.Now()
+		iterations := playComputerGame(board)
+		elapsed := time.Since(start)
+		fmt.Printf("Seed:       %d\n", opts.seed)
+		fmt.Printf("Score:      %s (base %d)\n", board.GetDisplayScore(), board.GetScore())
+		fmt.Printf("Lines:      %d\n", board.GetLines())
+		fmt.Printf("Iterations: %d\n", iterations)
+		fmt.Printf("Time:       %v (%v per iteration)\n", elapsed, elapsed/time.Duration(iterations))
+		fmt.Printf("Checksum:   %016x\n", board.Checksum())
+		if set["expect"] && (board.GetScore() != uint32(opts.expect)) {
+			fmt.Fprintf(os.Stderr, "expected a base score of %d, got %d\n", opts.expect, board.GetScore())
+			os.Exit(view.ERROR_BENCH)
+		}
+		os.Exit(view.EXIT_SUCCESS)
+	}
+
+	if mode == TEXT_MODE {
+		textGame.SetPreview(!opts.noPreview)
+		textGame.SetPreviewPosition(previewPositions[opts.preview])
+		textGame.SetHidden(opts.hidden)
+		textGame.SetColorProgression(opts.colorCycle)
+		textGame.SetGridLines(opts.grid)
+		textGame.SetHeatmap(opts.heatmap)
+		textGame.SetDropPath(opts.dropPath)
+		textGame.SetMouse(opts.mouse)
+		textGame.SetHint(opts.practice)
+		textGame.SetBigBlocks(opts.big)
+		textGame.SetZone(opts.zone)
+		textGame.SetEffects(!opts.noEffects)
+		textGame.SetAttractTimeout(opts.attract)
+		if opts.glyph != "" {
+			glyph, _ := utf8.DecodeRuneInString(opts.glyph)
+			textGame.SetBlockGlyph(glyph)
+		}
+		// Key bindings were checked when the preferences were loaded
+		bindings, _ := config.KeyBindings()
+		for key, action := range bindings {
+			textGame.SetKeyBinding(key, action)
+		}
+		if !textGame.SetColorScheme(opts.scheme) {
+			fmt.Fprintf(os.Stderr, "unknown color scheme %q\n", opts.scheme)
+			flags.Usage()
+			os.Exit(view.ERROR_USAGE)
+		}
+		// Record games in the high score table, if there is a place to keep it
+		if scoresPath, err := view.ScoreBoardPath(); err == nil {
+			scores, err := view.LoadScoreBoard(scoresPath)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
 				os.Exit(view.ERROR_USAGE)
 			}
+			initials := config.Initials
+			if initials == "" {
+				initials = os.Getenv("USER")
+			}
+			textGame.SetScoreBoard(&scores, scoresPath, initials)
+		}
+		// The title screen takes the place of the prompt on startup, and shows
+		// the demo game when it sits idle
+		if !opts.noMenu {
+			choice, play := textGame.RunMenu(view.MenuChoice{
+				GameMode: gameModeName(opts),
+				Level:    uint8(opts.level),
+			})
+			if !play {
+				textGame.ExitGame()
+				os.Exit(view.EXIT_SUCCESS)
+			}
+			setGameMode(choice.GameMode, &opts)
+			opts.level = uint(choice.Level)
+			// Remember the picks for next time. Preferences are a convenience,
+			// so failing to save them does not stop the game.
+			if configPath != "" {
+				config.GameMode = choice.GameMode
+				config.Level = choice.Level
+				view.SaveConfig(configPath, config)
+			}
 		}
 	}
 
 	// Initialize, run, and exit with the selected mode
 	playAgain := true
 	for playAgain {
-		modeMap[mode].InitGame(model.NewBoard())
+		modeMap[mode].InitGame(newGameBoard(opts, fixedSeed))
 		playAgain = modeMap[mode].RenderGame()
 	}
 	modeMap[mode].ExitGame()
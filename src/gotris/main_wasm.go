@@ -0,0 +1,33 @@
+//go:build js && wasm
+// +build js,wasm
+
+/*
+ * File:        main_wasm.go
+ *
+ * Author:      Schuyler Martin <schuylermartin45@gmail.com>
+ *
+ * Description: Main execution point of the `gotris` project, when built for the
+ *              browser.
+ */
+package main
+
+import (
+	"github.com/schuylermartin45/gotris/src/gotris/model"
+	"github.com/schuylermartin45/gotris/src/gotris/view"
+)
+
+/***** Functions *****/
+
+/*
+ Main entry point of the Gotris project in the browser. There are no command
+ line arguments to pick a mode with, so the browser mode is always used.
+*/
+func main() {
+	game := view.NewWASMGame()
+	playAgain := true
+	for playAgain {
+		game.InitGame(model.NewBoard())
+		playAgain = game.RenderGame()
+	}
+	game.ExitGame()
+}
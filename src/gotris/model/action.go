@@ -0,0 +1,101 @@
+/*
+ * File:        action.go
+ *
+ * Author:      Schuyler Martin <schuylermartin45@gmail.com>
+ *
+ * Description: Actions that can be performed on the Gotris board.
+ */
+package model
+
+/***** Types *****/
+
+// Action describes a user-caused event in the game.
+type Action uint8
+
+// Enumeration of actions
+const (
+	ActionIllegal  Action = 0
+	ActionLeft     Action = 1
+	ActionRight    Action = 2
+	ActionDown     Action = 3
+	ActionFastDown Action = 4
+	ActionRotate   Action = 5
+	ActionExit     Action = 6
+	// Saves a picture of the board. This is up to the view, the board ignores it
+	ActionScreenshot Action = 7
+)
+
+/***** Methods *****/
+
+/*
+ Performs an action on the board. Actions that do not move the tile (i.e.
+ `ActionExit` and `ActionScreenshot`) are ignored. Every action that changes the
+ board reports one event (i.e. `EventRotate`), so views can play a sound for it.
+
+ @param action Action to perform.
+
+ @return True if the action changed the board. False otherwise.
+*/
+func (b *Board) Apply(action Action) bool {
+	applied := false
+	switch action {
+	case ActionLeft:
+		applied = b.MoveLeft()
+	case ActionRight:
+		applied = b.MoveRight()
+	case ActionDown:
+		applied = b.MoveDown()
+	case ActionFastDown:
+		if b.tile != nil {
+			depth := b.tileDepth
+			b.MoveFastDown()
+			applied = b.tileDepth != depth
+		}
+	case ActionRotate:
+		applied = b.Rotate()
+	}
+	b.recordAction(action, applied)
+	if event, ok := actionEvents[action]; ok && applied {
+		b.fireEvent(event)
+	}
+	return applied
+}
+
+/*
+ Performs a sequence of actions on the board, i.e. for replaying a game.
+
+ @param actions Actions to perform, in order.
+ @param gravity True to advance the game by one iteration (as `Next()` does)
+                after every action, so gravity interleaves with the actions.
+                False to perform every action without the tile falling.
+
+ @return The grid after the last action AND true if the game ended. The
+         remaining actions are skipped once the game ends.
+*/
+func (b *Board) ApplyActions(actions []Action, gravity bool) ([]BoardRow, bool) {
+	for _, action := range actions {
+		b.Apply(action)
+		if !gravity {
+			continue
+		}
+		if grid, gameDone := b.Next(); gameDone {
+			return grid, true
+		}
+	}
+	return b.Current(), false
+}
+
+/*
+ Previews the result of an action, without changing the board. This is useful
+ for highlighting the outcome of a move before it is made.
+
+ @param action Action to preview.
+
+ @return The grid to display if the action were performed. Actions that are
+         not possible result in the current grid.
+*/
+func (b Board) PreviewMove(action Action) []BoardRow {
+	preview := b.Clone()
+	preview.Apply(action)
+	return preview.Current()
+}
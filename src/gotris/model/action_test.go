@@ -0,0 +1,93 @@
+/*
+ * File:        action_test.go
+ *
+ * Author:      Schuyler Martin <schuylermartin45@gmail.com>
+ *
+ * Description: Tests for performing actions on the board.
+ */
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+/***** Tests *****/
+
+/*
+ Dropping a tile in a column reports every move, and records them in the
+ history, as if the keys had been pressed.
+*/
+func TestDropInColumnEvents(t *testing.T) {
+	b := newTestBoard(t)
+	events := recordEvents(b)
+	b.EnableHistory(true)
+	spawnTile(t, b, Red)
+	tile, _, _ := b.GetActiveTile()
+	_, leftCol, _, _ := tile.BoundingBox()
+	if !b.Apply(ActionDown) {
+		t.Fatal("tile did not soft drop")
+	}
+	if !b.DropInColumn(leftCol - 2) {
+		t.Fatal("tile did not drop")
+	}
+	b.Next()
+	expectedEvents := []Event{EventSoftDrop, EventMove, EventMove, EventHardDrop, EventLock}
+	if len(*events) != len(expectedEvents) {
+		t.Fatalf("reported %v, expected %v", *events, expectedEvents)
+	}
+	for i := range expectedEvents {
+		if (*events)[i] != expectedEvents[i] {
+			t.Fatalf("reported %v, expected %v", *events, expectedEvents)
+		}
+	}
+	expectedActions := []Action{ActionDown, ActionLeft, ActionLeft, ActionFastDown}
+	history := b.History()
+	if len(history) != len(expectedActions) {
+		t.Fatalf("recorded %d actions, expected %d", len(history), len(expectedActions))
+	}
+	for i := range expectedActions {
+		if (history[i].Action != expectedActions[i]) || !history[i].Applied {
+			t.Errorf("recorded %+v, expected %d to be applied", history[i], expectedActions[i])
+		}
+	}
+}
+
+/*
+ Dropping a tile in a column it can't reach neither moves the tile, nor reports
+ or records anything.
+*/
+func TestDropInColumnBlocked(t *testing.T) {
+	// The stack walls off the left side of the board
+	b := newTestBoard(t, strings.Split(strings.Repeat("II........\n", int(BoardHeight)-2), "\n")[:BoardHeight-2]...)
+	events := recordEvents(b)
+	b.EnableHistory(true)
+	spawnTile(t, b, Red)
+	tile, depth, _ := b.GetActiveTile()
+	if b.DropInColumn(0) {
+		t.Fatal("tile dropped through the stack")
+	}
+	if moved, movedDepth, _ := b.GetActiveTile(); !moved.Equals(tile) || (movedDepth != depth) {
+		t.Error("tile moved")
+	}
+	if (len(*events) != 0) || (len(b.History()) != 0) {
+		t.Errorf("reported %v and recorded %v", *events, b.History())
+	}
+}
+
+/***** Internal Functions *****/
+
+/*
+ Records the events a board reports.
+
+ @param b Board to record.
+
+ @return The events reported, oldest first. Filled in as the board reports them.
+*/
+func recordEvents(b *Board) *[]Event {
+	events := new([]Event)
+	b.OnEvent(func(event Event) {
+		*events = append(*events, event)
+	})
+	return events
+}
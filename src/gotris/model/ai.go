@@ -0,0 +1,162 @@
+/*
+ * File:        ai.go
+ *
+ * Author:      Schuyler Martin <schuylermartin45@gmail.com>
+ *
+ * Description: A simple computer player, that picks where to place each tile.
+ */
+package model
+
+/***** Constants *****/
+
+// Weights the computer player scores a board with. Lower stacks with fewer
+// holes and a flatter surface are better, clearing rows is best.
+const (
+	aiHeightWeight    = -0.51
+	aiLinesWeight     = 0.76
+	aiHolesWeight     = -0.36
+	aiBumpinessWeight = -0.18
+)
+
+/***** Types *****/
+
+// Placement describes where to put a tile: how many times to rotate it and the
+// column its left-most block lands in.
+type Placement struct {
+	Rotations uint8
+	Column    uint8
+}
+
+/***** Internal Functions *****/
+
+/*
+ Calculates the height of the stack in each column.
+
+ @param grid Grid to measure.
+
+ @return Number of rows from the bottom of the board to the top-most block of
+         each column. 0 for empty columns.
+*/
+func calcColumnHeights(grid BoardGrid) [BoardWidth]uint8 {
+	var heights [BoardWidth]uint8
+	for col := uint8(0); col < BoardWidth; col++ {
+		for row := uint8(0); row < BoardHeight; row++ {
+			if getBlock(grid[row], col) != Transparent {
+				heights[col] = BoardHeight - row
+				break
+			}
+		}
+	}
+	return heights
+}
+
+/*
+ Scores a grid for the computer player.
+
+ @param grid    Grid to score, after a tile has been placed.
+ @param cleared Number of rows cleared by placing the tile.
+
+ @return The score of the grid. The higher, the better.
+*/
+func scoreGrid(grid BoardGrid, cleared uint16) float64 {
+	heights := calcColumnHeights(grid)
+	aggregateHeight, holes, bumpiness := 0, 0, 0
+	for col := uint8(0); col < BoardWidth; col++ {
+		aggregateHeight += int(heights[col])
+		// Every empty cell under the top of the column is a hole
+		for row := BoardHeight - heights[col]; row < BoardHeight; row++ {
+			if getBlock(grid[row], col) == Transparent {
+				holes++
+			}
+		}
+		if col > 0 {
+			diff := int(heights[col]) - int(heights[col-1])
+			if diff < 0 {
+				diff = -diff
+			}
+			bumpiness += diff
+		}
+	}
+	return (aiHeightWeight * float64(aggregateHeight)) +
+		(aiLinesWeight * float64(cleared)) +
+		(aiHolesWeight * float64(holes)) +
+		(aiBumpinessWeight * float64(bumpiness))
+}
+
+/***** Methods *****/
+
+/*
+ Finds the best place to put the current tile, by trying every rotation in every
+ column. Placements that score the same are broken by the left-most column, then
+ the fewest rotations, so the computer player always picks the same placement
+ for the same board.
+
+ @return The best placement AND true if a placement was found. False if no tile
+         is dropping or the tile can't be placed anywhere.
+*/
+func (b Board) BestPlacement() (Placement, bool) {
+	best := Placement{}
+	bestScore := 0.0
+	found := false
+	if b.tile == nil {
+		return best, false
+	}
+	// Placements are tried in tie-break order, so only a better score replaces
+	// the best placement.
+	for col := uint8(0); col < BoardWidth; col++ {
+		for rotations := uint8(0); rotations < 4; rotations++ {
+			placement := Placement{Rotations: rotations, Column: col}
+			trial := b.Clone()
+			if !trial.ApplyPlacement(placement) {
+				continue
+			}
+			// Merge the tile into the stack, to see which rows it clears
+			grid := trial.calcWorkingGrid()
+			cleared := uint16(0)
+			for _, rows := range trial.clearRows(grid) {
+				cleared += rows
+			}
+			score := scoreGrid(*grid, cleared)
+			if !found || (score > bestScore) {
+				best, bestScore, found = placement, score, true
+			}
+		}
+	}
+	return best, found
+}
+
+/*
+ Rotates the current tile and drops it in a column.
+
+ @param placement Where to put the tile.
+
+ @return True if the tile was placed. False if the tile could not be rotated or
+         moved into the column, in which case the tile may have been rotated.
+*/
+func (b *Board) ApplyPlacement(placement Placement) bool {
+	for i := uint8(0); i < placement.Rotations; i++ {
+		if !b.Apply(ActionRotate) {
+			return false
+		}
+	}
+	return b.DropInColumn(placement.Column)
+}
+
+/*
+ Finds the cells the current tile would fill at the computer player's best
+ placement, so views can suggest it to a player that is learning.
+
+ @return The cells of the tile at its best placement. Empty if no tile is
+         dropping or the tile can't be placed anywhere.
+*/
+func (b Board) HintCells() []Cell {
+	placement, found := b.BestPlacement()
+	if !found {
+		return nil
+	}
+	trial := b.Clone()
+	if !trial.ApplyPlacement(placement) {
+		return nil
+	}
+	return trial.tileCells()
+}
@@ -0,0 +1,104 @@
+/*
+ * File:        ai_test.go
+ *
+ * Author:      Schuyler Martin <schuylermartin45@gmail.com>
+ *
+ * Description: Tests and benchmarks for the computer player.
+ */
+package model
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+/***** Constants *****/
+
+// Base score the computer player ends the game with for seed 0. The same seed
+// always plays the same game, so any change to how the game plays changes it.
+const benchSeedScore = uint32(16670)
+
+/***** Tests *****/
+
+/*
+ The computer player plays the same game every time for the same seed.
+*/
+func TestComputerGameSeedScore(t *testing.T) {
+	board := NewBoardWithSeed(0)
+	iterations := playComputerGame(board)
+	if board.GetScore() != benchSeedScore {
+		t.Errorf("game ended with a base score of %d after %d iterations, expected %d", board.GetScore(), iterations, benchSeedScore)
+	}
+}
+
+/*
+ Two computer games from the same seed pick the same placements and leave the
+ board in the same state after every tile.
+*/
+func TestComputerGameTrace(t *testing.T) {
+	const placements = 200
+	var traces [2]bytes.Buffer
+	for i := range traces {
+		board := NewBoardWithSeed(5)
+		for placed := 0; placed < placements; {
+			if _, gameDone := board.Next(); gameDone {
+				break
+			}
+			if board.HardDropDistance() == 0 {
+				continue
+			}
+			placement, ok := board.BestPlacement()
+			if !ok {
+				continue
+			}
+			board.ApplyPlacement(placement)
+			placed++
+			fmt.Fprintf(&traces[i], "%+v %016x\n", placement, board.Checksum())
+		}
+	}
+	if traces[0].Len() == 0 {
+		t.Fatal("computer placed no tiles")
+	}
+	if !bytes.Equal(traces[0].Bytes(), traces[1].Bytes()) {
+		t.Errorf("games with the same seed played differently:\n%s\n%s", traces[0].String(), traces[1].String())
+	}
+}
+
+/*
+ Times the computer player playing a full game, with a fixed seed.
+*/
+func BenchmarkComputerGame(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		board := NewBoardWithSeed(0)
+		playComputerGame(board)
+		if board.GetScore() != benchSeedScore {
+			b.Fatalf("game ended with a base score of %d, expected %d", board.GetScore(), benchSeedScore)
+		}
+	}
+}
+
+/***** Internal Functions *****/
+
+/*
+ Has the computer play a game until it ends, placing every tile as soon as it
+ spawns.
+
+ @param board Board to play on.
+
+ @return The number of iterations the game lasted.
+*/
+func playComputerGame(board *Board) uint64 {
+	iterations := uint64(0)
+	for {
+		iterations++
+		if _, gameDone := board.Next(); gameDone {
+			return iterations
+		}
+		if board.HardDropDistance() > 0 {
+			if placement, ok := board.BestPlacement(); ok {
+				board.ApplyPlacement(placement)
+			}
+		}
+	}
+}
@@ -8,7 +8,9 @@
 package model
 
 import (
+	"encoding/binary"
 	"fmt"
+	"hash/fnv"
 	"math/rand"
 	// Ticking away, the moments that make up the dull day...
 	"time"
@@ -24,26 +26,66 @@ const (
 
 	/** Internal **/
 
-	// A full row
-	maskFullRow uint32 = 0xFFFFFFFF
-	// An empty row (with 2 bits unused)
-	maskRow2BitPad uint32 = 0x80000001
 	// Bit-size of one color-block
-	blockBitSize uint32 = 3
-	// Amount to shift a color or mask value to the right by to be in
-	// the leading (right-most) position in the board (1 bit left of the right
-	// most pad)
-	rShiftBlockBitDiff uint32 = 28
+	blockBitSize BoardRow = 3
+	// Amount to shift a color or mask value to the left by to be in the
+	// left-most column of the board (1 bit left of the right most pad)
+	rShiftBlockBitDiff BoardRow = (blockBitSize * BoardRow(BoardWidth-1)) + 1
+	// A full row
+	maskFullRow BoardRow = ^BoardRow(0)
+	// An empty row. Every bit outside of the board's columns is set, so tiles
+	// collide with the walls. There is always at least 1 bit of padding on each
+	// side, which limits the board to 20 columns.
+	maskRow2BitPad BoardRow = maskFullRow &^ (((1 << (blockBitSize * BoardRow(BoardWidth))) - 1) << 1)
 	// Mask used to detect blocks (will require left shifting to maintain
 	// bit interpretation order)
 	blockMask = 0b111
 )
 
+// Gravity defaults. A tile falls one row every gravity interval. The interval
+// shrinks by one step every level, until it reaches the floor.
+const (
+	DefaultGravityInterval time.Duration = 500 * time.Millisecond
+	DefaultGravityFloor    time.Duration = 100 * time.Millisecond
+
+	/** Internal **/
+
+	// Amount the gravity interval shrinks by per level
+	gravityLevelStep time.Duration = 50 * time.Millisecond
+)
+
+// Timing defaults. After a tile locks, filled rows stay on the board for the
+// line clear delay, so views can animate them. The next tile spawns after the
+// entry delay. The iteration a tile spawns on already gives the player a short
+// break, so there is no entry delay by default.
+const (
+	DefaultLineClearDelay time.Duration = 200 * time.Millisecond
+	DefaultEntryDelay     time.Duration = 0
+)
+
+// Lock delay limits. With a lock delay, a resting tile waits before it locks, and
+// every move or rotation restarts the wait. The wait can only be restarted so
+// many times, and a tile that has rested long enough locks no matter what, so a
+// tile can't be spun forever to stall the game.
+const (
+	MaxLockResets  uint8         = 15
+	MaxRestingTime time.Duration = 5 * time.Second
+)
+
+// Bonus added to the base score when a line clear empties the entire board,
+// indexed by the number of rows cleared at once.
+var perfectClearBonus = [TileSize + 1]uint16{0, 10, 15, 25, 35}
+
 /***** Types *****/
 
+// BoardRow is one row of blocks, packed as 3-bit colors from the left-most
+// column in the high bits to the right-most column in the low bits. Bits
+// outside of the board's columns are padding.
+type BoardRow uint64
+
 // BoardGrid is one unit taller than it's displayable form. This makes collision
 // detection easier.
-type BoardGrid [BoardHeight + 1]uint32
+type BoardGrid [BoardHeight + 1]BoardRow
 
 /*
  DrawBlock is a callback that renders a single block when called by
@@ -56,11 +98,31 @@ type BoardGrid [BoardHeight + 1]uint32
 */
 type DrawBlock func(row uint8, col uint8, isEOL bool, color TileColor)
 
+// Cell is the position of a single block on the board.
+type Cell struct {
+	Row uint8
+	Col uint8
+}
+
+// ClearedCell is a block that was cleared off of the board, and its color.
+type ClearedCell struct {
+	Row   uint8
+	Col   uint8
+	Color TileColor
+}
+
 // Board represents the primary state of the game.
 type Board struct {
 	grid BoardGrid
-	// Holds the base score. Display score is this value x100 (to look cooler)
-	score uint16
+	// Holds the base score. Display score is this value x100 (to look cooler).
+	// Long games score well past 16 bits, so this is kept wide.
+	score uint32
+	// Points scored by soft drops, one per row. These are display points, far
+	// smaller than the base score, so they are kept apart from it (and from the
+	// level, which is worked out from the base score).
+	dropPoints uint32
+	// Total number of rows cleared
+	lines uint16
 	// Reference to the current dropping tile. Nil means a new tile should be
 	// picked.
 	tile *Tile
@@ -70,8 +132,123 @@ type Board struct {
 	// Depth tracks how far down the current tile is in the board. 0 Means
 	// no tile has dropped.
 	tileDepth uint8
-	// Random number generator, initialized with the board.
+	// Rows of garbage waiting to rise once the dropping tile locks
+	pendingGarbage uint8
+	// In the zone, gravity stops and filled rows are banked at the bottom of
+	// the board instead of being cleared
+	inZone    bool
+	zoneLines uint8
+	// Random number generator, initialized with the board. Its source can be
+	// copied, so clones draw the same numbers.
 	random *rand.Rand
+	source *replaySource
+	// Picks the tiles that drop onto the board
+	randomizer Randomizer
+	// Time it takes a tile to fall one row at level 0 and the fastest gravity
+	// can get as levels increase.
+	gravityInterval time.Duration
+	gravityFloor    time.Duration
+	// Time accumulated by `Tick()` towards the next gravity drop.
+	gravityElapsed time.Duration
+	// Keeps the dropping tile in place, so it only moves on player input
+	noGravity bool
+	// Drops tiles to the floor as soon as they spawn or slide off of a ledge
+	instantGravity bool
+	// Locks a tile as soon as a soft drop lands it, and whether a soft drop has
+	// landed the tile since the last `Tick()`
+	softDropLock bool
+	lockPending  bool
+	// Time a resting tile waits before it locks. 0 locks it right away. While
+	// the tile rests, the time since the wait last restarted, the time the tile
+	// has rested in total, and the number of times the wait was restarted.
+	lockDelay   time.Duration
+	resting     bool
+	lockElapsed time.Duration
+	restElapsed time.Duration
+	lockResets  uint8
+	// Time filled rows stay on the board before they are cleared, and time
+	// before the next tile spawns after a lock
+	lineClearDelay time.Duration
+	entryDelay     time.Duration
+	// Time left on the current delay. Gravity waits until it runs out.
+	delayRemaining time.Duration
+	// Filled rows waiting out the line clear delay. Nil if no rows are waiting.
+	clearingRows []uint8
+	// Callback that is notified of game events
+	onEvent EventHandler
+	// Disables the grace iteration given when a new tile spawns
+	noSpawnGrace bool
+	// Rules the game is played by
+	mode GameMode
+	// Cells of the most recently locked tile. Empty once the next tile spawns.
+	lockedCells []Cell
+	// Blocks cleared after the most recent lock, with their colors
+	clearedCells []ClearedCell
+	// Flag indicates if the last tile to lock emptied the entire board
+	lastClearPerfect bool
+	// Spawns tiles in a random rotation
+	randomSpawnRotation bool
+	// How tiles rotate when they don't fit, and the number of clockwise turns
+	// the dropping tile has made since it spawned (0-3)
+	rotationSystem RotationSystem
+	rotationState  uint8
+	// How blocks fall after rows are cleared and the number of chain links the
+	// last tile to lock cleared rows in
+	clearGravity ClearGravity
+	chainLength  int
+	// Number of iterations the game has gone through, and the number of times
+	// the game has been advanced by `Tick()`
+	iteration uint32
+	frame     uint64
+	// Base score gained in the most recent iteration
+	scoreDelta uint32
+	// Scores locked tiles. Locks are scored with the tile's spin, the number of
+	// clearing locks in a row, and whether the last clear was difficult.
+	scorer         Scorer
+	lastMoveRotate bool
+	lastSpin       SpinType
+	combo          int
+	lastDifficult  bool
+	// Level the game starts at and the highest level the game can reach. A max
+	// level of 0 means there is no cap.
+	startLevel uint8
+	maxLevel   uint8
+	// Log of performed actions, recorded only when enabled
+	history        []HistoryEntry
+	historyEnabled bool
+}
+
+// QueueState bundles the tiles a view shows around the board, taken together so
+// they can't drift apart between calls.
+type QueueState struct {
+	// Tile that is dropping, its depth in the board, and whether one is dropping
+	Active    Tile
+	Depth     uint8
+	HasActive bool
+	// Tiles coming up, in the order they spawn. The board looks one tile ahead.
+	Next []Tile
+}
+
+// BoardState is a checkpoint of a board's game state. Being a value type, taking
+// a snapshot does not allocate, so the same state can cheaply be restored over
+// and over again.
+type BoardState struct {
+	grid       BoardGrid
+	score      uint32
+	dropPoints uint32
+	lines      uint16
+	tile       Tile
+	hasTile    bool
+	nextTile   Tile
+	hasNext    bool
+	tileDepth  uint8
+	// Clockwise turns the tile has made, so SRS kicks stay in step
+	rotationState uint8
+	// Rows of garbage waiting to rise
+	pendingGarbage uint8
+	// Whether the board is in the zone, and the rows banked there
+	inZone    bool
+	zoneLines uint8
 }
 
 /***** Functions *****/
@@ -83,21 +260,232 @@ type Board struct {
 */
 func NewBoard() *Board {
 	b := new(Board)
+	b.grid = newEmptyGrid()
+	// Set a new random generator per game. This ensures that we don't
+	// constantly reconstruct the generator for every random value we need.
+	b.seedRandom(time.Now().UnixNano())
+	b.gravityInterval = DefaultGravityInterval
+	b.gravityFloor = DefaultGravityFloor
+	b.lineClearDelay = DefaultLineClearDelay
+	b.entryDelay = DefaultEntryDelay
+	b.mode = ClassicMode{}
+	b.scorer = GuidelineScorer
+	return b
+}
+
+/*
+ Constructs a Gotris board for practicing digging out of a mess. The bottom of
+ the board is filled with rows of garbage before play begins. The garbage (and
+ the tiles that follow) are the same every time for a given seed, so the same
+ scenario can be retried.
+
+ @param seed Seed for the random number generator.
+ @param rows Number of garbage rows to start with.
+
+ @return A board, pre-filled with garbage.
+*/
+func NewBoardWithGarbage(seed int64, rows uint8) *Board {
+	b := NewBoardWithSeed(seed)
+	b.AddGarbageLines(rows)
+	return b
+}
+
+/*
+ Constructs a Gotris board with a seeded random number generator. The tiles are
+ picked in the same order every time for a given seed.
+
+ @param seed Seed for the random number generator.
+
+ @return A Gotris board, seeded for reproducible games.
+*/
+func NewBoardWithSeed(seed int64) *Board {
+	b := NewBoard()
+	b.seedRandom(seed)
+	return b
+}
+
+/*
+ Constructs a Gotris board that picks tiles with a specific randomizer. This
+ lets tests script the tiles that drop.
+
+ @param randomizer Picks the tiles that drop onto the board.
+
+ @return A Gotris board, picking tiles with the randomizer.
+*/
+func NewBoardWithRandomizer(randomizer Randomizer) *Board {
+	b := NewBoard()
+	b.randomizer = randomizer
+	return b
+}
+
+/*
+ Turns a code typed by a player into a seed, so codes like "2024-06-01" or
+ "cool-game" can be shared instead of numbers. A code always gives the same
+ seed.
+
+ @param code Code to turn into a seed.
+
+ @return The seed for the code.
+*/
+func SeedFromString(code string) int64 {
+	hash := fnv.New64a()
+	hash.Write([]byte(code))
+	return int64(hash.Sum64())
+}
+
+/*
+ Checks if a tile can be placed on a grid without overlapping placed blocks or
+ leaving the board.
+
+ @param grid  Grid to place the tile on.
+ @param tile  Tile to place.
+ @param depth Depth the tile is placed at, as tracked by the board.
+
+ @return True if the tile fits. False if it collides with the grid.
+*/
+func TileFits(grid BoardGrid, tile Tile, depth uint8) bool {
+	// Depths past the floor would index outside of the grid
+	if int(depth)-int(tile.GetBottomGap()) > int(BoardHeight) {
+		return false
+	}
+	return !checkCollisions(grid, tile, depth)
+}
+
+/***** Internal Functions *****/
+
+/*
+ Flips a row of blocks horizontally. Padding bits are kept as they are.
+
+ @param row Row to flip.
+
+ @return The flipped row.
+*/
+func mirrorRow(row BoardRow) BoardRow {
+	mirrored := row & maskRow2BitPad
+	for col := uint8(0); col < BoardWidth; col++ {
+		mirrored = setBlock(mirrored, BoardWidth-1-col, getBlock(row, col))
+	}
+	return mirrored
+}
+
+/*
+ Constructs the randomizer boards pick tiles with by default. The randomizer gets
+ its own random number generator, seeded from the board's, so the tiles dealt do
+ not depend on when the board uses its own generator (i.e. for garbage).
+
+ @param random Board's random number generator.
+
+ @return A new bag randomizer.
+*/
+func newDefaultRandomizer(random *rand.Rand) Randomizer {
+	source := newReplaySource(random.Int63())
+	return &BagRandomizer{random: rand.New(source), source: source}
+}
+
+/*
+ Compares two optional tiles.
+
+ @param a First tile. Nil if there is no tile.
+ @param b Second tile. Nil if there is no tile.
+
+ @return True if neither tile is set or both tiles are the same.
+*/
+func equalTiles(a *Tile, b *Tile) bool {
+	if (a == nil) || (b == nil) {
+		return a == b
+	}
+	return *a == *b
+}
+
+/*
+ Restores a tile reference from a checkpoint, re-using the existing tile when
+ possible.
+
+ @param current Current tile reference.
+ @param tile    Tile saved in the checkpoint.
+ @param isSet   Flag indicates if the tile was set when the checkpoint was taken.
+
+ @return The restored tile reference.
+*/
+func restoreTile(current *Tile, tile Tile, isSet bool) *Tile {
+	if !isSet {
+		return nil
+	}
+	if current == nil {
+		current = new(Tile)
+	}
+	*current = tile
+	return current
+}
+
+/*
+ Constructs a grid with no blocks on it.
+
+ @return An empty grid.
+*/
+func newEmptyGrid() BoardGrid {
+	var grid BoardGrid
 	// Since we have 2 bits we can't do anything with, we pad each side
 	// of the board by 1 bit
 	for i := 0; i < int(BoardHeight); i++ {
-		b.grid[i] = maskRow2BitPad
+		grid[i] = maskRow2BitPad
 	}
 	// Last grid row (which is not drawn) is full of 1s for easier
 	// collision detection.
-	b.grid[BoardHeight] = maskFullRow
-	// Set a new random generator per game. This ensures that we don't
-	// constantly reconstruct the generator for every random value we need.
-	b.random = rand.New(rand.NewSource(time.Now().UnixNano()))
-	return b
+	grid[BoardHeight] = maskFullRow
+	return grid
 }
 
-/***** Internal Functions *****/
+/*
+ Checks if a grid has no blocks on it.
+
+ @param grid Grid to check.
+
+ @return True if every displayable row of the grid is empty. False otherwise.
+*/
+func isGridEmpty(grid BoardGrid) bool {
+	for row := uint8(0); row < BoardHeight; row++ {
+		if grid[row] != maskRow2BitPad {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+ Finds the rows of a grid that are filled.
+
+ @param grid Grid to search.
+
+ @return The filled rows, counting down from the top of the board. Nil if no
+         rows are filled.
+*/
+func findFullRows(grid *BoardGrid) []uint8 {
+	var rows []uint8
+	for row := uint8(0); row < BoardHeight; row++ {
+		if calcCollisionRow(grid[row]) == maskFullRow {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+/*
+ Constructs a row of garbage. Garbage rows are full, except for a single gap.
+
+ @param gap Column that is left empty.
+
+ @return The garbage row.
+*/
+func newGarbageRow(gap uint8) BoardRow {
+	row := maskRow2BitPad
+	for col := uint8(0); col < BoardWidth; col++ {
+		if col != gap {
+			row |= BoardRow(Grey) << (rShiftBlockBitDiff - (blockBitSize * BoardRow(col)))
+		}
+	}
+	return row
+}
 
 /*
  Helper function that calculates a "collision row", which is a row that
@@ -112,9 +500,9 @@ func NewBoard() *Board {
  @return The original row, but all color data replaced with "full" blocks,
          (value: `0b11`)
 */
-func calcCollisionRow(row uint32) uint32 {
-	var mask uint32 = blockMask << rShiftBlockBitDiff
-	collisionRow := uint32(0)
+func calcCollisionRow(row BoardRow) BoardRow {
+	var mask BoardRow = blockMask << rShiftBlockBitDiff
+	collisionRow := BoardRow(0)
 	for col := uint8(0); col < BoardWidth; col++ {
 		if (mask & row) > 0 {
 			collisionRow |= mask
@@ -127,6 +515,21 @@ func calcCollisionRow(row uint32) uint32 {
 	return collisionRow
 }
 
+/*
+ Calculates the depth a tile spawns at. Tiles spawn with their top row of blocks
+ flush against the top of the board, regardless of any empty rows at the top or
+ bottom of the tile's block structure.
+
+ @param tile Tile to spawn.
+
+ @return The depth to spawn the tile at.
+*/
+func calcSpawnDepth(tile Tile) uint8 {
+	// The bottom of the tile is drawn at the depth minus the bottom gap. The
+	// bottom gap cancels out when accounting for the tile's height.
+	return TileSize - tile.GetTopGap() - 1
+}
+
 /*
  Check collisions given a future version of the board and tile.
 
@@ -176,7 +579,7 @@ func checkCollisions(grid BoardGrid, tile Tile, tileDepth uint8) bool {
  @param width  Width of the blocks array. If this is shorter than `BoardWidth`,
                the tile will attempt to be vertically centered
 */
-func renderBlocks(draw DrawBlock, blocks []uint32, height uint8, width uint8) {
+func renderBlocks(draw DrawBlock, blocks []BoardRow, height uint8, width uint8) {
 	// Padding calculation for width
 	widthDiff := uint8(0)
 	if BoardWidth > width {
@@ -186,17 +589,17 @@ func renderBlocks(draw DrawBlock, blocks []uint32, height uint8, width uint8) {
 	}
 	halfWidthDiff := widthDiff / 2
 	for row := uint8(0); row < height; row++ {
-		var mask uint32 = blockMask << (rShiftBlockBitDiff - (blockBitSize * uint32(widthDiff/2)))
+		var mask BoardRow = blockMask << (rShiftBlockBitDiff - (blockBitSize * BoardRow(widthDiff/2)))
 		paddedWidth := width + halfWidthDiff
 		for col := uint8(halfWidthDiff); col < paddedWidth; col++ {
 			// Select one block at a time, determine the color
 			color := Transparent
 			// Non-zero values require additional shifting
-			singleBlock := uint32(blocks[row] & mask)
+			singleBlock := blocks[row] & mask
 			if singleBlock > 0 {
 				// Shift to the far right, so the bit can be interpretted as a
 				// color. +1 is for the right-most extra bit.
-				shiftBy := (blockBitSize * uint32((BoardWidth-1)-col)) + 1
+				shiftBy := (blockBitSize * BoardRow((BoardWidth-1)-col)) + 1
 				color = TileColor(singleBlock >> shiftBy)
 			}
 			isEOL := col >= (paddedWidth - 1)
@@ -209,205 +612,1103 @@ func renderBlocks(draw DrawBlock, blocks []uint32, height uint8, width uint8) {
 /***** Methods *****/
 
 /*
- Get the displayable version of the score.
+ Makes a copy of the board that can be changed without affecting the original.
+ The copy draws from copies of the original's random number generator and
+ randomizer, so it picks the same tiles as the original without changing the
+ tiles the original picks. Randomizers that can't be copied (i.e. ones provided
+ to `NewBoardWithRandomizer()`) are shared. The copy does not report events and
+ does not record history.
 
- @return The game's current score as a displayable string
+ @return A copy of the board.
 */
-func (b Board) GetDisplayScore() string {
-	return fmt.Sprintf("%06d", b.score) + "00"
+func (b Board) Clone() *Board {
+	clone := b
+	if b.tile != nil {
+		tile := *b.tile
+		clone.tile = &tile
+	}
+	if b.nextTile != nil {
+		nextTile := *b.nextTile
+		clone.nextTile = &nextTile
+	}
+	if b.source != nil {
+		clone.source = b.source.fork()
+		clone.random = rand.New(clone.source)
+	}
+	if randomizer, ok := b.randomizer.(forkableRandomizer); ok {
+		clone.randomizer = randomizer.fork()
+	}
+	clone.onEvent = nil
+	clone.history = nil
+	clone.historyEnabled = false
+	return &clone
 }
 
 /*
- Get the current level. The higher the level, the fast the game.
+ Takes a checkpoint of the board's game state (grid, score, tiles, and depth).
 
- For added fun (and in the spirit of Pacman) the level counter will be 8 bits
- longs. So if someone manages to get it that high, they'll start back at level
- one.
-
- @return The game's current level.
+ @return The current state of the board.
 */
-func (b Board) GetLevel() uint8 {
-	// Every ten cleared rows gets new level.
-	return uint8(b.score / 10)
+func (b Board) Snapshot() BoardState {
+	state := BoardState{
+		grid:           b.grid,
+		score:          b.score,
+		dropPoints:     b.dropPoints,
+		lines:          b.lines,
+		tileDepth:      b.tileDepth,
+		rotationState:  b.rotationState,
+		pendingGarbage: b.pendingGarbage,
+		inZone:         b.inZone,
+		zoneLines:      b.zoneLines,
+	}
+	if b.tile != nil {
+		state.tile = *b.tile
+		state.hasTile = true
+	}
+	if b.nextTile != nil {
+		state.nextTile = *b.nextTile
+		state.hasNext = true
+	}
+	return state
 }
 
 /*
- Get the next tile (for preview rendering purposes)
+ Overwrites the board's game state with a checkpoint.
 
- @return A copy of the next tile for rendering
+ @param state State to restore, taken by `Snapshot()`.
 */
-func (b Board) GetNextTile() Tile {
-	// If nil, return an empty tile
-	if b.nextTile == nil {
-		return Tile{}
-	}
-	return *b.nextTile
+func (b *Board) Restore(state BoardState) {
+	b.grid = state.grid
+	b.score = state.score
+	b.dropPoints = state.dropPoints
+	b.lines = state.lines
+	b.tileDepth = state.tileDepth
+	b.rotationState = state.rotationState
+	b.pendingGarbage = state.pendingGarbage
+	b.inZone = state.inZone
+	b.zoneLines = state.zoneLines
+	b.tile = restoreTile(b.tile, state.tile, state.hasTile)
+	b.nextTile = restoreTile(b.nextTile, state.nextTile, state.hasNext)
 }
 
 /*
- Moves the current tile to the left, if possible.
+ Compares the game state of two boards: the grid, score, level, lines, dropping
+ tile with its depth and rotation state, next tile, pending garbage, and zone.
+ Random number generators, settings, and callbacks are not compared, so a board
+ equals its clone until either one plays on.
 
- @return True if the move happened. False otherwise.
+ @param other Board to compare against.
+
+ @return True if both boards are in the same game state.
 */
-func (b *Board) MoveLeft() bool {
-	return b.moveX(Left)
+func (b Board) Equal(other *Board) bool {
+	return (other != nil) &&
+		(b.grid == other.grid) &&
+		(b.score == other.score) &&
+		(b.dropPoints == other.dropPoints) &&
+		(b.GetLevel() == other.GetLevel()) &&
+		(b.lines == other.lines) &&
+		(b.tileDepth == other.tileDepth) &&
+		(b.rotationState == other.rotationState) &&
+		(b.pendingGarbage == other.pendingGarbage) &&
+		(b.inZone == other.inZone) &&
+		(b.zoneLines == other.zoneLines) &&
+		equalTiles(b.tile, other.tile) &&
+		equalTiles(b.nextTile, other.nextTile)
 }
 
 /*
- Moves the current tile to the right, if possible.
+ Hashes the same game state that `Equal()` compares, so boards that are equal
+ hash the same. The hash is stable across runs and platforms, so replays and
+ networked games can record it and check that they have not diverged.
 
- @return True if the move happened. False otherwise.
+ @return A 64-bit FNV-1a hash of the game state.
 */
-func (b *Board) MoveRight() bool {
-	return b.moveX(Right)
+func (b Board) Checksum() uint64 {
+	hash := fnv.New64a()
+	var buf [8]byte
+	writeUint := func(value uint64) {
+		binary.LittleEndian.PutUint64(buf[:], value)
+		hash.Write(buf[:])
+	}
+	writeTile := func(tile *Tile) {
+		if tile == nil {
+			writeUint(0)
+			return
+		}
+		writeUint(uint64(tile.color))
+		for _, row := range tile.shape {
+			writeUint(uint64(row))
+		}
+	}
+	for _, row := range b.grid {
+		writeUint(uint64(row))
+	}
+	writeUint(uint64(b.score))
+	writeUint(uint64(b.dropPoints))
+	writeUint(uint64(b.GetLevel()))
+	writeUint(uint64(b.lines))
+	writeUint(uint64(b.tileDepth))
+	writeUint(uint64(b.rotationState))
+	writeTile(b.tile)
+	writeTile(b.nextTile)
+	writeUint(uint64(b.pendingGarbage))
+	inZone := uint64(0)
+	if b.inZone {
+		inZone = 1
+	}
+	writeUint(inZone)
+	writeUint(uint64(b.zoneLines))
+	return hash.Sum64()
 }
 
 /*
- Moves the tile down one additional unit, if possible.
+ Moves the stack of placed blocks down, discarding rows that fall off the bottom
+ of the board. Empty rows fill in from the top. The phantom row under the board
+ is left in place, and the dropping tile does not move with the stack. Rows
+ banked in the zone stay at the bottom, the stack is discarded on top of them.
 
- @return True if the move happened. False otherwise.
+ @param rows Number of rows to shift the stack by.
 */
-func (b *Board) MoveDown() bool {
-	if b.tile == nil {
-		return false
+func (b *Board) ShiftDown(rows uint8) {
+	bottom := BoardHeight - b.zoneLines
+	if rows > bottom {
+		rows = bottom
 	}
-	tempDepth := b.tileDepth + 1
-	if checkCollisions(b.grid, *b.tile, tempDepth) {
-		return false
+	for row := int(bottom) - 1; row >= int(rows); row-- {
+		b.grid[row] = b.grid[row-int(rows)]
+	}
+	for row := uint8(0); row < rows; row++ {
+		b.grid[row] = maskRow2BitPad
 	}
-	b.tileDepth = tempDepth
-	return true
 }
 
 /*
- Moves the tile down until a colission occurs
+ Flips the board horizontally: the stack of placed blocks and the dropping tile.
+ The tile keeps its color, so flipped tiles may look like other shapes.
 */
-func (b *Board) MoveFastDown() {
-	if b.tile == nil {
-		return
+func (b *Board) Mirror() {
+	for row := uint8(0); row < BoardHeight; row++ {
+		b.grid[row] = mirrorRow(b.grid[row])
 	}
-	for b.MoveDown() {
+	if b.tile != nil {
+		for row := range b.tile.shape {
+			b.tile.shape[row] = mirrorRow(b.tile.shape[row])
+		}
 	}
 }
 
 /*
- Rotates the current tile, if possible.
+ Pushes the stack of placed blocks up, filling in the bottom of the board with
+ rows of garbage. Each garbage row has a single gap in a random column. This
+ should be called between tiles, as the dropping tile does not move with the
+ stack. In the zone, the garbage is queued up until the zone is exited, so it
+ does not rise under the banked rows.
 
- @return True if the move happened. False otherwise.
+ @param rows Number of garbage rows to add.
+
+ @return True if the stack still fits on the board. False if blocks were pushed
+         off of the top of the board.
 */
-func (b *Board) Rotate() bool {
-	if b.tile == nil {
-		return false
+func (b *Board) AddGarbageLines(rows uint8) bool {
+	if b.inZone {
+		b.QueueGarbage(rows)
+		return true
 	}
-	tempTile := *b.tile
-	// Bail if the rotation is impossible
-	if !tempTile.Rotate() {
-		return false
+	if rows > BoardHeight {
+		rows = BoardHeight
 	}
-	// Bail if a collision occurred
-	if checkCollisions(b.grid, tempTile, b.tileDepth) {
-		return false
+	// Rows pushed off of the top of the board are lost
+	fits := true
+	for row := uint8(0); row < rows; row++ {
+		if b.grid[row] != maskRow2BitPad {
+			fits = false
+		}
 	}
-	*b.tile = tempTile
-	return true
+	for row := uint8(0); row < (BoardHeight - rows); row++ {
+		b.grid[row] = b.grid[row+rows]
+	}
+	for row := BoardHeight - rows; row < BoardHeight; row++ {
+		b.grid[row] = newGarbageRow(uint8(b.random.Intn(int(BoardWidth))))
+	}
+	return fits
 }
 
 /*
- Handle the next iteration of the game. Coupled with the primary game loop,
- this makes the game work.
-
- @return The current grid to display AND true if the game has ended.
+ Empties the board, removing every placed block and the dropping tile. The score,
+ level, and lines are kept, so play carries on with a fresh board. Rows waiting
+ to be cleared or banked in the zone are thrown away with the rest of the blocks.
 */
-func (b *Board) Next() ([]uint32, bool) {
-	// Initialize the next tile. This should a 1-time cost on first starting the
-	// game. This simplifies the logic for setting the active tile.
-	if b.nextTile == nil {
-		b.nextTile = PickTile(b.random)
-	}
-	// On completion of a move, the next tile becomes the active and a new next
-	// is picked.
-	if b.tile == nil {
-		b.tile = b.nextTile
-		b.nextTile = PickTile(b.random)
-		b.tileDepth = 0
-		// Skip the rest of this iteration to give the user a break. Also ensures
-		// that the `tileDepth` variable stays "in sync" with the actual row array
-		// index.
-		return b.grid[:BoardHeight], false
-	}
-
-	// Track conditions for moving to the next tile. In other words, a collision
-	// has been detected.
-	tileDone := false
-	// Track if the game is done ("We're in the end game now, Stark")
-	gameDone := false
+func (b *Board) Clear() {
+	b.grid = newEmptyGrid()
+	b.tile = nil
+	b.tileDepth = 0
+	b.rotationState = 0
+	b.lockedCells = nil
+	b.clearingRows = nil
+	b.delayRemaining = 0
+	b.zoneLines = 0
+}
 
-	// Calculate the current state of the grid.
-	workingGrid := b.calcWorkingGrid()
-	// If a collision is detected in the next move, then we stop here and move
-	// to the next tile.
-	if checkCollisions(b.grid, *b.tile, b.tileDepth+1) {
-		tileDone = true
-		// The game ends when a collision is detected on a tile that has yet
-		// to drop into the board.
-		if b.tileDepth < uint8(len(b.tile.shape)) {
-			gameDone = true
-		}
-	}
+/*
+ Queues up rows of garbage, for modes where garbage is sent by an opponent. The
+ garbage rises before the next tile spawns, unless rows cleared by the dropping
+ tile cancel it out first.
 
-	// Advance to the next tile. Tile becomes persistently part of the board
-	if tileDone {
-		b.tile = nil
-		// Search for filled rows, clear them, shift above rows down.
-		// Remember that there is a phantom row at the bottom of the board that is
-		// not rendered.
-		numCleared := uint16(0)
-		for row := int8(BoardHeight - 1); row >= 0; row-- {
-			if calcCollisionRow(workingGrid[row]) == maskFullRow {
-				for i := row; i >= 1; i-- {
-					workingGrid[i] = workingGrid[i-1]
-				}
-				// Top row gets wiped clean.
-				workingGrid[0] = maskRow2BitPad
-				// Count the cleared rows.
-				numCleared++
-				// Reset row calculation to run against the same row again.
-				// In the event that multiple rows are cleared at once, this
-				// prevents us from leaving a full row beind.
-				row++
-			}
-		}
-		// Get a score multiplier if multiple rows are cleared at once.
-		b.score += numCleared * numCleared
-		// If you cleared a row, play the terminal bell for fun
-		if numCleared > 0 {
-			fmt.Print("\a")
-		}
-		b.grid = *workingGrid
-	} else {
-		b.tileDepth++
+ @param rows Number of garbage rows to queue up. The queue holds at most a
+             board's height of garbage.
+*/
+func (b *Board) QueueGarbage(rows uint8) {
+	if rows > (BoardHeight - b.pendingGarbage) {
+		rows = BoardHeight - b.pendingGarbage
 	}
-	return workingGrid[:BoardHeight], gameDone
+	b.pendingGarbage += rows
 }
 
 /*
- Get the current state of the board, without moving to the next iteration.
+ Get the rows of garbage waiting to rise, so views can warn the player.
 
- @return The current grid to display.
+ @return The number of queued garbage rows.
 */
-func (b Board) Current() []uint32 {
-	// If no tile is set, then the working grid is all that is needed to be
-	// displayed.
-	if b.tile == nil {
-		return b.grid[:BoardHeight]
-	}
-
-	return b.calcWorkingGrid()[:BoardHeight]
+func (b Board) PendingGarbage() uint8 {
+	return b.pendingGarbage
 }
 
 /*
- Given a callback, this function iterates over the board and executes the
- the callback to render a block on the board.
+ Checks the board for a corrupt state. Every row must have its padding bits
+ set, the phantom row under the board must be full, and the dropping tile must
+ be within the board. Color codes always fit in their 3 bits, so any color code
+ is valid.
+
+ @return An error describing the first problem found. Nil if the board is valid.
+*/
+func (b Board) Validate() error {
+	for row := uint8(0); row < BoardHeight; row++ {
+		if (b.grid[row] & maskRow2BitPad) != maskRow2BitPad {
+			return fmt.Errorf("row %d is missing its padding bits: %#016x", row, b.grid[row])
+		}
+	}
+	if b.grid[BoardHeight] != maskFullRow {
+		return fmt.Errorf("phantom row is not full: %#016x", b.grid[BoardHeight])
+	}
+	if b.tile == nil {
+		return nil
+	}
+	for row, blocks := range b.tile.shape {
+		if (blocks & maskRow2BitPad) != 0 {
+			return fmt.Errorf("tile row %d sets padding bits: %#016x", row, blocks)
+		}
+	}
+	// The bottom of the tile is drawn at the depth minus the bottom gap
+	bottomGap := b.tile.GetBottomGap()
+	if (b.tileDepth > bottomGap) && ((b.tileDepth - bottomGap) >= BoardHeight) {
+		return fmt.Errorf("tile depth %d is below the board", b.tileDepth)
+	}
+	return nil
+}
+
+/*
+ Get the displayable version of the score. The displayed score is the raw score
+ x100, plus a point for every row soft dropped.
+
+ @return The game's current score as a displayable string
+*/
+func (b Board) GetDisplayScore() string {
+	return fmt.Sprintf("%08d", (uint64(b.score)*100)+uint64(b.dropPoints))
+}
+
+/*
+ Get the points scored by soft drops. These are display points, so they are
+ added to the raw score x100 to get the points shown by `GetDisplayScore()`.
+
+ @return The number of rows soft dropped this game.
+*/
+func (b Board) GetDropPoints() uint32 {
+	return b.dropPoints
+}
+
+/*
+ Get the raw score, as kept by the board. Multiply by 100 and add
+ `GetDropPoints()` to get the points shown by `GetDisplayScore()`. High score
+ tables and stats should keep raw scores, so they stay correct if the display
+ ever changes.
+
+ @return The game's current raw score.
+*/
+func (b Board) GetScore() uint32 {
+	return b.score
+}
+
+/*
+ Get the current level. The higher the level, the fast the game.
+
+ For added fun (and in the spirit of Pacman) the level counter will be 8 bits
+ longs. So if someone manages to get it that high, they'll start back at level
+ one. That is, unless the level is capped by `SetMaxLevel()`.
+
+ @return The game's current level.
+*/
+func (b Board) GetLevel() uint8 {
+	// Every ten cleared rows gets new level.
+	level := uint32(b.startLevel) + uint32(b.lines/10)
+	if (b.maxLevel > 0) && (level > uint32(b.maxLevel)) {
+		return b.maxLevel
+	}
+	return uint8(level)
+}
+
+/*
+ Sets the level the game starts at, for players that want a faster game from the
+ start. Levels gained during play are added on top of it.
+
+ @param level Level to start at. Defaults to 0.
+*/
+func (b *Board) SetStartLevel(level uint8) {
+	b.startLevel = level
+}
+
+/*
+ Caps the level, so the level (and the speed of gravity) stops increasing once
+ the cap is reached.
+
+ @param level Highest level the game can reach. 0 removes the cap (the default).
+*/
+func (b *Board) SetMaxLevel(level uint8) {
+	b.maxLevel = level
+}
+
+/*
+ Get the points scored in the most recent iteration of the game. Like the base
+ score, this is x100 when displayed.
+
+ @return The base score gained by the last call to `Next()` or `Tick()`. Points
+         scored by every iteration a tick runs are added together.
+*/
+func (b Board) LastScoreDelta() uint32 {
+	return b.scoreDelta
+}
+
+/*
+ Get the height of the stack of placed blocks in every column, for warning the
+ player where the stack is getting tall. The dropping tile is not included.
+
+ @return Number of rows from the bottom of the board to the top-most block of
+         each column. 0 for empty columns, up to `BoardHeight`.
+*/
+func (b Board) Heatmap() [BoardWidth]uint8 {
+	return calcColumnHeights(b.grid)
+}
+
+/*
+ Get which columns of a row are filled with placed blocks. The dropping tile is
+ not included.
+
+ @param row Row of the board, counting down from the top.
+
+ @return One bit per column, the lowest bit being the left-most column. A set
+         bit is a filled cell. 0 for rows outside of the board.
+*/
+func (b Board) RowFillMask(row uint8) uint32 {
+	if row >= BoardHeight {
+		return 0
+	}
+	mask := uint32(0)
+	for col := uint8(0); col < BoardWidth; col++ {
+		if getBlock(b.grid[row], col) != Transparent {
+			mask |= 1 << col
+		}
+	}
+	return mask
+}
+
+/*
+ Get the width of the board, so views don't have to assume the board's size.
+
+ @return The number of columns on the board.
+*/
+func (b Board) Width() uint8 {
+	return BoardWidth
+}
+
+/*
+ Get the height of the board, so views don't have to assume the board's size.
+
+ @return The number of rows on the board.
+*/
+func (b Board) Height() uint8 {
+	return BoardHeight
+}
+
+/*
+ Get the number of rows cleared so far.
+
+ @return The total number of cleared rows.
+*/
+func (b Board) GetLines() uint16 {
+	return b.lines
+}
+
+/*
+ Get the time it takes for a tile to fall one row at the current level.
+
+ @return The current gravity interval.
+*/
+func (b Board) GetGravityInterval() time.Duration {
+	interval := b.gravityInterval - (time.Duration(b.GetLevel()) * gravityLevelStep)
+	if interval < b.gravityFloor {
+		return b.gravityFloor
+	}
+	return interval
+}
+
+/*
+ Configures how fast tiles fall.
+
+ @param interval Time it takes a tile to fall one row at level 0.
+ @param floor    Shortest interval gravity can reach as the level increases.
+*/
+func (b *Board) SetGravity(interval time.Duration, floor time.Duration) {
+	b.gravityInterval = interval
+	b.gravityFloor = floor
+}
+
+/*
+ Configures the pauses after a tile locks. Both delays are only waited out by
+ `Tick()`. Calling `Next()` directly moves on right away.
+
+ @param lineClear Time filled rows stay on the board before they are cleared,
+                  so views can animate them. 0 clears rows as the tile locks.
+ @param entry     Time before the next tile spawns, after any rows are cleared.
+*/
+func (b *Board) SetDelays(lineClear time.Duration, entry time.Duration) {
+	b.lineClearDelay = lineClear
+	b.entryDelay = entry
+}
+
+/*
+ Sets whether gravity pulls the dropping tile down. Without gravity, the tile
+ only moves on player input. Tiles still spawn and lock in place once they rest
+ on the stack, so tutorials can have the player place a tile exactly.
+
+ @param enabled True to let tiles fall (the default). False to keep them in
+                place.
+*/
+func (b *Board) SetGravityEnabled(enabled bool) {
+	b.noGravity = !enabled
+}
+
+/*
+ Sets whether gravity is instant ("20G"), a challenge mode. Tiles drop to the
+ floor as soon as they spawn and can only be slid along the stack. Tiles still
+ lock on the next gravity drop, which gives a moment to slide them.
+
+ @param enabled True for instant gravity. False for tiles to fall a row at a
+                time (the default).
+*/
+func (b *Board) SetInstantGravity(enabled bool) {
+	b.instantGravity = enabled
+}
+
+/*
+ Sets whether a soft drop that lands the tile locks it right away. By default,
+ a landed tile can still slide until gravity pulls on it again, which casual
+ players expect. Competitive players often want the tile to lock instantly.
+ Hard drops are not affected.
+
+ @param enabled True to lock tiles as soon as a soft drop lands them. False to
+                wait on gravity (the default).
+*/
+func (b *Board) SetSoftDropLock(enabled bool) {
+	b.softDropLock = enabled
+}
+
+/*
+ Sets how long a tile waits after it comes to rest before it locks. Moving or
+ rotating a resting tile restarts the wait, up to `MaxLockResets` times, and a
+ tile locks once it has rested for `MaxRestingTime` in total. With a lock delay,
+ a hard drop locks the tile on the next `Tick()`. The lock delay only applies to
+ `Tick()`, as `Next()` has no notion of time.
+
+ @param delay Time a resting tile waits before it locks. 0 locks the tile as
+              soon as gravity finds it resting (the default).
+*/
+func (b *Board) SetLockDelay(delay time.Duration) {
+	b.lockDelay = delay
+}
+
+/*
+ Get the rules the game is played by.
+
+ @return The board's game mode.
+*/
+func (b Board) GetGameMode() GameMode {
+	return b.mode
+}
+
+/*
+ Sets the rules the game is played by.
+
+ @param mode Game mode to play.
+*/
+func (b *Board) SetGameMode(mode GameMode) {
+	b.mode = mode
+}
+
+/*
+ Sets how blocks above cleared rows fall.
+
+ @param mode `NaiveShift` to shift rows down (the default) or `Sticky` to let
+             groups of connected blocks fall until they land.
+*/
+func (b *Board) SetClearGravity(mode ClearGravity) {
+	b.clearGravity = mode
+}
+
+/*
+ Sets whether a new tile gets a grace iteration. With the grace iteration, the
+ iteration that spawns a tile does not move it, so the tile is flush against the
+ top of the board after spawning. Without it, the tile falls in the same
+ iteration it spawns, so its depth is one row further down after spawning.
+
+ @param enabled True to give new tiles a grace iteration (the default). False
+                to start dropping tiles right away.
+*/
+func (b *Board) SetSpawnGraceEnabled(enabled bool) {
+	b.noSpawnGrace = !enabled
+}
+
+/*
+ Sets whether tiles spawn in a random rotation, for variety. The rotation is
+ picked with the board's random number generator, so it is reproducible for a
+ given seed.
+
+ @param enabled True to spawn tiles in a random rotation. False to always spawn
+                tiles in their starting orientation (the default).
+*/
+func (b *Board) SetRandomSpawnRotation(enabled bool) {
+	b.randomSpawnRotation = enabled
+}
+
+/*
+ Re-seeds the board from a code typed by a player, so everyone playing the same
+ code (i.e. the day's date, for a daily challenge) gets the same tiles. This
+ should be set before the game starts, as tiles already picked are thrown away.
+
+ @param code Code to seed the board with. See `SeedFromString()`.
+*/
+func (b *Board) SetSeedFromString(code string) {
+	b.seedRandom(SeedFromString(code))
+	b.nextTile = nil
+}
+
+/*
+ Get the tile that is currently dropping.
+
+ @return A copy of the active tile, its depth in the board, AND true if a tile is
+         active. False if no tile is dropping.
+*/
+func (b Board) GetActiveTile() (Tile, uint8, bool) {
+	if b.tile == nil {
+		return Tile{}, 0, false
+	}
+	return *b.tile, b.tileDepth, true
+}
+
+/*
+ Get the color of the tile that is currently dropping, without copying the tile.
+
+ @return The color of the active tile AND true if a tile is active. False if no
+         tile is dropping.
+*/
+func (b Board) ActiveTileColor() (TileColor, bool) {
+	if b.tile == nil {
+		return Transparent, false
+	}
+	return b.tile.color, true
+}
+
+/*
+ Sets the next tile to a specific shape, instead of a random one. The tile spawns
+ on the next iteration that picks a new tile. This lets tutorials control which
+ tile comes next.
+
+ @param color Identifying color of the tile to spawn.
+
+ @return An error if no tile has the color (i.e. `Transparent`).
+*/
+func (b *Board) ForceSpawn(color TileColor) error {
+	tile, ok := lookupTile(color)
+	if !ok {
+		return fmt.Errorf("no tile has the color %d", color)
+	}
+	b.nextTile = &tile
+	return nil
+}
+
+/*
+ Get the next tile (for preview rendering purposes)
+
+ @return A copy of the next tile for rendering
+*/
+func (b Board) GetNextTile() Tile {
+	// If nil, return an empty tile
+	if b.nextTile == nil {
+		return Tile{}
+	}
+	return *b.nextTile
+}
+
+/*
+ Get the dropping tile and the tiles coming up, all at once.
+
+ @return The state of the tile queue.
+*/
+func (b Board) QueueState() QueueState {
+	state := QueueState{}
+	state.Active, state.Depth, state.HasActive = b.GetActiveTile()
+	if b.nextTile != nil {
+		state.Next = []Tile{*b.nextTile}
+	}
+	return state
+}
+
+/*
+ Looks ahead at the tiles that will spawn next, for long previews and look-ahead
+ computer players. The tiles are picked from a copy of the randomizer, so
+ peeking does not change the game, and the tiles that spawn match the tiles
+ peeked at. Randomizers that can't be copied (i.e. ones provided to
+ `NewBoardWithRandomizer()`) can't be looked ahead in, so only the next tile is
+ known.
+
+ @param n Number of tiles to look ahead.
+
+ @return The colors identifying up to the next `n` tiles to spawn, starting
+         with the next tile.
+*/
+func (b Board) PeekNext(n int) []TileColor {
+	var colors []TileColor
+	if (b.nextTile != nil) && (n > 0) {
+		colors = append(colors, b.nextTile.color)
+	}
+	randomizer, ok := b.randomizer.(forkableRandomizer)
+	if !ok {
+		return colors
+	}
+	forked := randomizer.fork()
+	for len(colors) < n {
+		colors = append(colors, forked.NextTile().color)
+	}
+	return colors
+}
+
+/*
+ Moves the current tile to the left, if possible.
+
+ @return True if the move happened. False otherwise.
+*/
+func (b *Board) MoveLeft() bool {
+	return b.moveX(Left)
+}
+
+/*
+ Moves the current tile to the right, if possible.
+
+ @return True if the move happened. False otherwise.
+*/
+func (b *Board) MoveRight() bool {
+	return b.moveX(Right)
+}
+
+/*
+ Moves the current tile to the left several times, stopping early at a wall or
+ the stack.
+
+ @param n Number of columns to move by.
+
+ @return Number of columns the tile moved.
+*/
+func (b *Board) MoveLeftN(n uint8) uint8 {
+	return b.moveXN(Left, n)
+}
+
+/*
+ Moves the current tile to the right several times, stopping early at a wall or
+ the stack.
+
+ @param n Number of columns to move by.
+
+ @return Number of columns the tile moved.
+*/
+func (b *Board) MoveRightN(n uint8) uint8 {
+	return b.moveXN(Right, n)
+}
+
+/*
+ Moves the tile down one additional unit, if possible. This is a soft drop, so
+ it scores a display point, and with soft drop locking enabled, a tile that
+ lands locks on the next `Tick()`.
+
+ @return True if the move happened. False otherwise.
+*/
+func (b *Board) MoveDown() bool {
+	moved := b.moveDown()
+	if moved {
+		b.dropPoints++
+	}
+	if b.softDropLock && (b.tile != nil) && checkCollisions(b.grid, *b.tile, b.tileDepth+1) {
+		b.lockPending = true
+	}
+	return moved
+}
+
+/*
+ Moves the tile down until a colission occurs. With a lock delay, the tile locks
+ on the next `Tick()`.
+*/
+func (b *Board) MoveFastDown() {
+	if b.tile == nil {
+		return
+	}
+	for b.moveDown() {
+	}
+	// A hard drop does not wait out the lock delay
+	if b.lockDelay > 0 {
+		b.lockPending = true
+	}
+}
+
+/*
+ Calculates how far the current tile would fall on a hard drop, without moving
+ the tile.
+
+ @return Number of rows the tile would descend. 0 if no tile is dropping.
+*/
+func (b Board) HardDropDistance() uint8 {
+	if b.tile == nil {
+		return 0
+	}
+	depth := b.tileDepth
+	for !checkCollisions(b.grid, *b.tile, depth+1) {
+		depth++
+	}
+	return depth - b.tileDepth
+}
+
+/*
+ Calculates every cell the current tile passes through on a hard drop, from
+ where it is now to where it lands. Views can draw this as a trail.
+
+ @return The cells the tile passes through, including where it is now and where
+         it lands. Cells already filled by the stack and cells above the board
+         are left out. Empty if no tile is dropping.
+*/
+func (b Board) DropPathCells() []Cell {
+	if b.tile == nil {
+		return nil
+	}
+	var cells []Cell
+	seen := make(map[Cell]bool)
+	// The board is a copy, so the tile can be moved down without affecting the
+	// original.
+	landing := b.tileDepth + b.HardDropDistance()
+	for ; b.tileDepth <= landing; b.tileDepth++ {
+		for _, cell := range b.tileCells() {
+			if seen[cell] || (getBlock(b.grid[cell.Row], cell.Col) != Transparent) {
+				continue
+			}
+			seen[cell] = true
+			cells = append(cells, cell)
+		}
+	}
+	return cells
+}
+
+/*
+ Slides the current tile until its left-most block is in a column, then drops
+ it to the floor. This lets click-to-drop interfaces place a tile in one call.
+ The moves are performed as actions, so they report events and are recorded in
+ the history like moves made with keys.
+
+ @param col Column to drop the tile in.
+
+ @return True if the tile was dropped. False if the column can't be reached, in
+         which case the tile does not move.
+*/
+func (b *Board) DropInColumn(col uint8) bool {
+	if (b.tile == nil) || (col >= BoardWidth) {
+		return false
+	}
+	// Find the moves that reach the column before making any of them
+	tile := *b.tile
+	var moves []Action
+	_, leftCol, _, _ := tile.BoundingBox()
+	for leftCol != col {
+		direction, move := Right, ActionRight
+		if leftCol > col {
+			direction, move = Left, ActionLeft
+		}
+		// Bail if the tile hit a wall or another block
+		var slid bool
+		if tile, slid = b.slideTile(tile, direction); !slid {
+			return false
+		}
+		moves = append(moves, move)
+		_, leftCol, _, _ = tile.BoundingBox()
+	}
+	for _, move := range moves {
+		b.Apply(move)
+	}
+	b.Apply(ActionFastDown)
+	return true
+}
+
+/*
+ Rotates the current tile clockwise, if possible. If the rotated tile does not
+ fit, the rotation system picks whether it fails or is nudged into a spot where
+ it fits.
+
+ @return True if the move happened. False otherwise.
+*/
+func (b *Board) Rotate() bool {
+	if b.tile == nil {
+		return false
+	}
+	tempTile := *b.tile
+	tempDepth := b.tileDepth
+	// Bail if the rotation is impossible
+	if !tempTile.Rotate() {
+		return false
+	}
+	switch b.rotationSystem {
+	case SRS:
+		var fits bool
+		if tempTile, tempDepth, fits = b.kickSRS(tempTile); !fits {
+			return false
+		}
+	default:
+		// Bail if a collision occurred
+		if !TileFits(b.grid, tempTile, tempDepth) {
+			return false
+		}
+	}
+	*b.tile = tempTile
+	b.tileDepth = tempDepth
+	b.rotationState = (b.rotationState + 1) % 4
+	b.lastMoveRotate = true
+	b.restartLockDelay()
+	return true
+}
+
+/*
+ Handle the next iteration of the game. Coupled with the primary game loop,
+ this makes the game work.
+
+ @return The current grid to display AND true if the game has ended.
+*/
+func (b *Board) Next() ([]BoardRow, bool) {
+	b.scoreDelta = 0
+	return b.next()
+}
+
+/*
+ Advances the game by an amount of elapsed time. Every time the gravity interval
+ elapses, the game moves to the next iteration. This lets every view share the
+ same gravity by simply reporting how much time has passed.
+
+ @param dt Time elapsed since the last call.
+
+ @return The current grid to display AND true if the game has ended.
+*/
+func (b *Board) Tick(dt time.Duration) ([]BoardRow, bool) {
+	b.frame++
+	// Points add up over every iteration this tick runs
+	b.scoreDelta = 0
+	if b.mode.OnTick(b, dt) {
+		return b.Current(), true
+	}
+	// Gravity waits while rows are cleared and before the next tile spawns
+	for b.delayRemaining > 0 {
+		if dt < b.delayRemaining {
+			b.delayRemaining -= dt
+			return b.Current(), false
+		}
+		dt -= b.delayRemaining
+		b.delayRemaining = 0
+		if b.clearingRows != nil {
+			b.finishClear(&b.grid)
+			b.delayRemaining = b.entryDelay
+		} else {
+			// Spawn the next tile as soon as the entry delay is over
+			b.gravityElapsed = b.GetGravityInterval()
+		}
+	}
+	// A soft drop that landed the tile locks it without waiting on gravity, as
+	// long as the tile is still resting on something
+	if b.lockPending {
+		b.lockPending = false
+		if (b.tile != nil) && checkCollisions(b.grid, *b.tile, b.tileDepth+1) {
+			b.gravityElapsed = 0
+			if grid, gameDone := b.next(); gameDone {
+				return grid, true
+			}
+			if b.delayRemaining > 0 {
+				return b.Current(), false
+			}
+		}
+	}
+	// A resting tile waits out the lock delay, then locks right away
+	if b.waitToLock(dt) {
+		b.gravityElapsed = 0
+		return b.Current(), false
+	} else if b.resting {
+		b.gravityElapsed = b.GetGravityInterval()
+	} else {
+		b.gravityElapsed += dt
+	}
+	for b.gravityElapsed >= b.GetGravityInterval() {
+		b.gravityElapsed -= b.GetGravityInterval()
+		if grid, gameDone := b.next(); gameDone {
+			return grid, true
+		}
+		// A tile locked, so gravity waits until the delay is over
+		if b.delayRemaining > 0 {
+			b.gravityElapsed = 0
+			break
+		}
+		// A tile that just landed waits out the lock delay
+		if (b.lockDelay > 0) && (b.tile != nil) && checkCollisions(b.grid, *b.tile, b.tileDepth+1) {
+			b.gravityElapsed = 0
+			break
+		}
+	}
+	b.applyInstantGravity()
+	return b.Current(), false
+}
+
+/*
+ Get the number of frames the game has been advanced by. Every call to `Tick()`
+ is a frame, no matter how much time passed, so views and replays can time
+ effects by frame instead of by the clock.
+
+ @return Number of frames played. 0 for a new board.
+*/
+func (b Board) Frame() uint64 {
+	return b.frame
+}
+
+/*
+ Get the current state of the board, without moving to the next iteration.
+
+ @return The current grid to display.
+*/
+func (b Board) Current() []BoardRow {
+	// If no tile is set, then the working grid is all that is needed to be
+	// displayed.
+	if b.tile == nil {
+		return b.grid[:BoardHeight]
+	}
+
+	return b.calcWorkingGrid()[:BoardHeight]
+}
+
+/*
+ Visits every cell of the placed blocks, from the top left to the bottom right.
+ Unlike rendering, the dropping tile is left out.
+
+ @param fn Callback given each cell's row, column, and color (`Transparent` if
+           the cell is empty). Returning false stops the iteration.
+*/
+func (b Board) ForEachCell(fn func(row uint8, col uint8, color TileColor) bool) {
+	for row := uint8(0); row < BoardHeight; row++ {
+		for col := uint8(0); col < BoardWidth; col++ {
+			if !fn(row, col, getBlock(b.grid[row], col)) {
+				return
+			}
+		}
+	}
+}
+
+/*
+ Checks if the most recently locked tile cleared every block off of the board.
+
+ @return True if the last tile to lock emptied the board. False otherwise.
+*/
+func (b Board) LastClearWasPerfect() bool {
+	return b.lastClearPerfect
+}
+
+/*
+ Get the length of the chain of clears set off by the most recently locked tile.
+ With sticky clear gravity, falling blocks can fill more rows, which are cleared
+ as the next link in the chain.
+
+ @return Number of rounds of rows the last tile to lock cleared. 0 if it cleared
+         no rows.
+*/
+func (b Board) LastChainLength() int {
+	return b.chainLength
+}
+
+/*
+ Checks if filled rows are waiting out the line clear delay.
+
+ @return True while rows are being cleared. False otherwise.
+*/
+func (b Board) IsClearing() bool {
+	return b.clearingRows != nil
+}
+
+/*
+ Get how far along the line clear delay is, so views can pace a clear
+ animation.
+
+ @return Fraction of the line clear delay that has passed, from 0 to 1. 0 if no
+         rows are being cleared.
+*/
+func (b Board) ClearProgress() float64 {
+	if !b.IsClearing() || (b.lineClearDelay <= 0) {
+		return 0
+	}
+	return 1 - (float64(b.delayRemaining) / float64(b.lineClearDelay))
+}
+
+/*
+ Get the filled rows that are waiting out the line clear delay, so views can
+ animate them before they are cleared.
+
+ @return Rows being cleared, counting down from the top of the board. Empty if
+         no rows are being cleared.
+*/
+func (b Board) ClearingRows() []uint8 {
+	return append([]uint8(nil), b.clearingRows...)
+}
+
+/*
+ Get the cells of the most recently locked tile, so views can highlight where
+ the tile landed. Cells are reported at the position the tile locked in, before
+ any rows were cleared.
+
+ @return The locked tile's cells. Empty once the next tile has spawned.
+*/
+func (b Board) LastLockedCells() []Cell {
+	return b.lockedCells
+}
+
+/*
+ Get the blocks cleared after the most recent lock, so views can burst effects
+ where they were, in their colors. Blocks are reported at their position before
+ rows were cleared. With sticky gravity, only the rows the lock filled are
+ reported, not rows filled later in the chain.
+
+ @return The cleared blocks. Empty if the most recent lock cleared nothing.
+*/
+func (b Board) LastClearedCells() []ClearedCell {
+	return b.clearedCells
+}
+
+/*
+ Given a callback, this function iterates over the board and executes the
+ the callback to render a block on the board.
 
  @param draw Callback to draw a block at a row, column position with a specific
              color.
@@ -416,6 +1717,51 @@ func (b Board) RenderBoard(draw DrawBlock) {
 	renderBlocks(draw, b.Current(), BoardHeight, BoardWidth)
 }
 
+/*
+ Given a callback, this function executes the callback only for the blocks that
+ have changed since a previous capture of the board, so renderers can redraw just
+ what changed instead of the whole board.
+
+ @param prev Rows of the board when it was last drawn, as given by `Current()`.
+             Rows missing from `prev` are drawn in full.
+ @param draw Callback to draw a block at a row, column position with a specific
+             color.
+*/
+func (b Board) RenderRegion(prev []BoardRow, draw DrawBlock) {
+	cur := b.Current()
+	for row := uint8(0); row < BoardHeight; row++ {
+		hasPrev := int(row) < len(prev)
+		if hasPrev && (prev[row] == cur[row]) {
+			continue
+		}
+		for col := uint8(0); col < BoardWidth; col++ {
+			color := getBlock(cur[row], col)
+			if hasPrev && (getBlock(prev[row], col) == color) {
+				continue
+			}
+			draw(row, col, col >= (BoardWidth-1), color)
+		}
+	}
+}
+
+/*
+ Given a callback, this function iterates over the board and executes the
+ the callback to render a block on the board. Blocks that have already been
+ placed are reported as `Transparent`, only the dropping tile is visible.
+
+ Collisions still take hidden blocks into account, only rendering changes.
+
+ @param draw Callback to draw a block at a row, column position with a specific
+             color.
+*/
+func (b Board) RenderBoardHidden(draw DrawBlock) {
+	grid := newEmptyGrid()
+	if b.tile != nil {
+		grid = *b.mergeTile(grid)
+	}
+	renderBlocks(draw, grid[:BoardHeight], BoardHeight, BoardWidth)
+}
+
 /*
  Given a callback, this function iterates over the next tile and executes the
  the callback to render a block.
@@ -424,12 +1770,150 @@ func (b Board) RenderBoard(draw DrawBlock) {
              color.
 */
 func (b Board) RenderNextTile(draw DrawBlock) {
-	blocks := b.GetNextTile().shape
-	renderBlocks(draw, blocks[:], TileSize, BoardWidth-2)
+	b.GetNextTile().Render(draw)
 }
 
 /***** Internal Methods *****/
 
+/*
+ Seeds the board's random number generator, and the default randomizer from it.
+
+ @param seed Seed for the random number generator.
+*/
+func (b *Board) seedRandom(seed int64) {
+	b.source = newReplaySource(seed)
+	b.random = rand.New(b.source)
+	b.randomizer = newDefaultRandomizer(b.random)
+}
+
+/*
+ Handles the next iteration of the game, like `Next()`, without resetting the
+ points scored. `Tick()` may run several iterations, so it resets them once.
+
+ @return The current grid to display AND true if the game has ended.
+*/
+func (b *Board) next() ([]BoardRow, bool) {
+	b.iteration++
+	// Stop waiting on any rows that are being cleared
+	b.delayRemaining = 0
+	if b.clearingRows != nil {
+		b.finishClear(&b.grid)
+	}
+	// Initialize the next tile. This should a 1-time cost on first starting the
+	// game. This simplifies the logic for setting the active tile.
+	if b.nextTile == nil {
+		b.nextTile = b.pickTile()
+	}
+	// On completion of a move, the next tile becomes the active and a new next
+	// is picked.
+	if b.tile == nil {
+		// Queued garbage rises between tiles, as the stack moves under it. In the
+		// zone, it waits until the banked rows are released.
+		if (b.pendingGarbage > 0) && !b.inZone {
+			fits := b.AddGarbageLines(b.pendingGarbage)
+			b.pendingGarbage = 0
+			if !fits && b.mode.OnGameOver(b) {
+				return b.grid[:BoardHeight], true
+			}
+		}
+		b.tile = b.nextTile
+		b.nextTile = b.pickTile()
+		b.lockedCells = nil
+		b.rotationState = 0
+		b.lastMoveRotate = false
+		b.resting = false
+		b.lockElapsed = 0
+		b.restElapsed = 0
+		b.lockResets = 0
+		if b.randomSpawnRotation {
+			b.rotateSpawnedTile()
+		}
+		b.tileDepth = calcSpawnDepth(*b.tile)
+		// The game ends when there is no room left for the new tile, unless the
+		// game mode makes room.
+		if checkCollisions(b.grid, *b.tile, b.tileDepth) && b.mode.OnGameOver(b) {
+			return b.calcWorkingGrid()[:BoardHeight], true
+		}
+		b.applyInstantGravity()
+		// Skip the rest of this iteration to give the user a break. Also ensures
+		// that the `tileDepth` variable stays "in sync" with the actual row array
+		// index.
+		if !b.noSpawnGrace {
+			return b.grid[:BoardHeight], false
+		}
+	}
+
+	// Track conditions for moving to the next tile. In other words, a collision
+	// has been detected.
+	tileDone := false
+	// Track if the game is done ("We're in the end game now, Stark")
+	gameDone := false
+	// Track if the tile stopped before fully dropping into the board
+	toppedOut := false
+
+	// Calculate the current state of the grid.
+	workingGrid := b.calcWorkingGrid()
+	// If a collision is detected in the next move, then we stop here and move
+	// to the next tile.
+	if checkCollisions(b.grid, *b.tile, b.tileDepth+1) {
+		tileDone = true
+		// The game ends when a collision is detected on a tile that has yet
+		// to fully drop into the board.
+		toppedOut = !b.isTileOnBoard()
+	}
+
+	// Advance to the next tile. Tile becomes persistently part of the board
+	if tileDone {
+		// Record where the tile landed, before rows are cleared out from under it.
+		b.lockedCells = b.tileCells()
+		b.lastSpin = b.detectSpin()
+		b.fireEvent(EventLock)
+		b.tile = nil
+		// Filled rows stay on the board for the line clear delay. A tile that
+		// tops out ends the game right away, so there is nothing to wait for.
+		// In the zone, filled rows are banked instead of cleared.
+		b.clearingRows = findFullRows(workingGrid)
+		if b.inZone {
+			b.bankRows(workingGrid)
+			b.clearingRows = nil
+			b.delayRemaining = b.entryDelay
+		} else if (b.clearingRows != nil) && (b.lineClearDelay > 0) && !toppedOut {
+			b.delayRemaining = b.lineClearDelay
+		} else {
+			b.finishClear(workingGrid)
+			b.delayRemaining = b.entryDelay
+		}
+		b.grid = *workingGrid
+		// Let the game mode decide if the game is really over.
+		if toppedOut {
+			gameDone = b.mode.OnGameOver(b)
+			workingGrid = &b.grid
+		}
+	} else if !b.noGravity && !b.inZone {
+		b.tileDepth++
+		b.lastMoveRotate = false
+	}
+	return workingGrid[:BoardHeight], gameDone
+}
+
+/*
+ Helper function that moves the tile down one unit, without soft drop locking.
+
+ @return True if the move happened. False otherwise.
+*/
+func (b *Board) moveDown() bool {
+	if b.tile == nil {
+		return false
+	}
+	tempDepth := b.tileDepth + 1
+	if checkCollisions(b.grid, *b.tile, tempDepth) {
+		return false
+	}
+	b.tileDepth = tempDepth
+	b.lastMoveRotate = false
+	return true
+}
+
 /*
  Helper function that moves in either X direction.
 
@@ -439,15 +1923,272 @@ func (b *Board) moveX(direction XDirection) bool {
 	if b.tile == nil {
 		return false
 	}
-	tempTile := *b.tile
-	tempTile.MoveX(direction)
-	if checkCollisions(b.grid, tempTile, b.tileDepth) {
+	tempTile, slid := b.slideTile(*b.tile, direction)
+	if !slid {
 		return false
 	}
 	*b.tile = tempTile
+	b.lastMoveRotate = false
+	b.restartLockDelay()
 	return true
 }
 
+/*
+ Slides a tile one column over at the current tile's depth, without moving the
+ current tile.
+
+ @param tile      Tile to slide.
+ @param direction Direction to slide in.
+
+ @return The tile after sliding AND true if it fits. The tile does not move when
+         it is up against a wall or the stack.
+*/
+func (b Board) slideTile(tile Tile, direction XDirection) (Tile, bool) {
+	tempTile := tile
+	tempTile.MoveX(direction)
+	if tempTile.Equals(tile) || checkCollisions(b.grid, tempTile, b.tileDepth) {
+		return tile, false
+	}
+	return tempTile, true
+}
+
+/*
+ Restarts the lock delay of a resting tile that moved, unless the tile has used
+ up its restarts.
+*/
+func (b *Board) restartLockDelay() {
+	if b.resting && (b.lockResets < MaxLockResets) {
+		b.lockElapsed = 0
+		b.lockResets++
+	}
+}
+
+/*
+ Advances the lock delay of the current tile, if it is resting.
+
+ @param dt Time elapsed since the last call.
+
+ @return True if the tile is resting and has yet to wait out the lock delay.
+         False if the tile is not resting or is ready to lock.
+*/
+func (b *Board) waitToLock(dt time.Duration) bool {
+	if (b.lockDelay == 0) || (b.tile == nil) || !checkCollisions(b.grid, *b.tile, b.tileDepth+1) {
+		b.resting = false
+		b.lockElapsed = 0
+		return false
+	}
+	b.resting = true
+	b.lockElapsed += dt
+	b.restElapsed += dt
+	return (b.lockElapsed < b.lockDelay) && (b.restElapsed < MaxRestingTime)
+}
+
+/*
+ Drops the current tile to the floor, if gravity is instant.
+*/
+func (b *Board) applyInstantGravity() {
+	if !b.instantGravity || (b.tile == nil) {
+		return
+	}
+	for b.moveDown() {
+	}
+}
+
+/*
+ Helper function that moves several times in either X direction.
+
+ @param direction Direction to move in.
+ @param n         Number of columns to move by.
+
+ @return Number of columns the tile moved.
+*/
+func (b *Board) moveXN(direction XDirection, n uint8) uint8 {
+	moved := uint8(0)
+	for (moved < n) && b.moveX(direction) {
+		moved++
+	}
+	return moved
+}
+
+/*
+ Clears filled rows after a tile locks, and scores them. Each round of clearing
+ rows is a link in a chain.
+
+ @param grid Grid the tile locked into. Cleared rows are removed from it.
+*/
+func (b *Board) finishClear(grid *BoardGrid) {
+	b.clearingRows = nil
+	// Track the level and score to detect when the player levels up and how
+	// many points were scored.
+	prevLevel := b.GetLevel()
+	prevScore := b.score
+	// Record the blocks in filled rows before they are cleared away
+	b.clearedCells = nil
+	for _, row := range findFullRows(grid) {
+		for col := uint8(0); col < BoardWidth; col++ {
+			b.clearedCells = append(b.clearedCells, ClearedCell{Row: row, Col: col, Color: getBlock(grid[row], col)})
+		}
+	}
+	// Search for filled rows, clear them, and let the blocks above fall.
+	chain := b.clearRows(grid)
+	b.chainLength = len(chain)
+	numCleared := uint16(0)
+	tetris := false
+	spin := b.lastSpin
+	if len(chain) == 0 {
+		// Locks that clear nothing end the combo, but can still score a spin
+		b.combo = 0
+		b.score += uint32(b.scorer(0, spin, 0, prevLevel, false))
+	}
+	for link, cleared := range chain {
+		// Every link of a chain continues the combo. Only the first link was
+		// spun into place. Links deeper into the chain are worth more.
+		difficult := (cleared >= uint16(TileSize)) || (spin != SpinNone)
+		backToBack := difficult && b.lastDifficult
+		points := uint32(b.scorer(uint8(cleared), spin, b.combo, prevLevel, backToBack))
+		b.score += points * uint32(link+1)
+		b.lastDifficult = difficult
+		b.combo++
+		spin = SpinNone
+		numCleared += cleared
+		tetris = tetris || (cleared >= uint16(TileSize))
+	}
+	// Every cleared row cancels a row of queued garbage
+	if numCleared >= uint16(b.pendingGarbage) {
+		b.pendingGarbage = 0
+	} else {
+		b.pendingGarbage -= uint8(numCleared)
+	}
+	b.scoreClearedRows(grid, numCleared, tetris, prevLevel, prevScore)
+}
+
+/*
+ Counts the rows cleared off of a grid, scores a perfect clear, and reports the
+ clear. Shared by clears after a lock and releases from the zone.
+
+ @param grid       Grid the rows were cleared from.
+ @param numCleared Number of rows cleared.
+ @param tetris     True if a Tetris was cleared.
+ @param prevLevel  Level before the rows were cleared.
+ @param prevScore  Score before the rows were cleared.
+*/
+func (b *Board) scoreClearedRows(grid *BoardGrid, numCleared uint16, tetris bool, prevLevel uint8, prevScore uint32) {
+	b.lines += numCleared
+	// Clearing every block off of the board earns a bonus.
+	b.lastClearPerfect = (numCleared > 0) && isGridEmpty(*grid)
+	if b.lastClearPerfect {
+		// Sticky gravity can clear more rows than a tile is tall
+		bonus := perfectClearBonus[TileSize]
+		if numCleared < uint16(TileSize) {
+			bonus = perfectClearBonus[numCleared]
+		}
+		b.score += uint32(bonus)
+	}
+	b.scoreDelta += b.score - prevScore
+	if numCleared > 0 {
+		b.fireEvent(EventLinesCleared)
+	}
+	if tetris {
+		b.fireEvent(EventTetris)
+	}
+	if b.GetLevel() != prevLevel {
+		b.fireEvent(EventLevelUp)
+	}
+	if b.lastClearPerfect {
+		b.fireEvent(EventPerfectClear)
+	}
+}
+
+/*
+ Picks the next tile.
+
+ @return A new tile, in its starting orientation.
+*/
+func (b *Board) pickTile() *Tile {
+	tile := b.randomizer.NextTile()
+	return &tile
+}
+
+/*
+ Rotates a freshly spawned tile 0-3 times at random. If the rotated tile does not
+ fit at the top of the board, the tile keeps its starting orientation.
+*/
+func (b *Board) rotateSpawnedTile() {
+	tempTile := *b.tile
+	turns := uint8(b.random.Intn(4))
+	for i := uint8(0); i < turns; i++ {
+		tempTile.Rotate()
+	}
+	if !checkCollisions(b.grid, tempTile, calcSpawnDepth(tempTile)) {
+		*b.tile = tempTile
+		b.rotationState = turns
+	}
+}
+
+/*
+ Clears filled rows from a grid, letting the blocks above fall according to the
+ board's clear gravity.
+
+ @param grid Grid to clear rows from.
+
+ @return Number of rows cleared by each link of the chain of clears, in order.
+         Empty if no rows were cleared.
+*/
+func (b Board) clearRows(grid *BoardGrid) []uint16 {
+	if b.clearGravity == Sticky {
+		return clearRowsSticky(grid)
+	}
+	if cleared := clearRowsNaive(grid); cleared > 0 {
+		return []uint16{cleared}
+	}
+	return nil
+}
+
+/*
+ Checks if the current tile has fully dropped into the visible board.
+
+ @return True if every block of the tile is on the board. False if part of the
+         tile is still above the board.
+*/
+func (b Board) isTileOnBoard() bool {
+	bottomGap := b.tile.GetBottomGap()
+	// Row the bottom of the tile is drawn on, taking the gap into account the
+	// same way collision detection does.
+	bottomRow := b.tileDepth
+	if bottomRow > bottomGap {
+		bottomRow -= bottomGap
+	}
+	height := TileSize - b.tile.GetTopGap() - bottomGap
+	return (bottomRow + 1) >= height
+}
+
+/*
+ Calculates the cells the current dropping tile occupies on the board. Blocks
+ that are still above the board are left out.
+
+ @return The cells covered by the current tile.
+*/
+func (b Board) tileCells() []Cell {
+	var cells []Cell
+	// Rows are mapped onto the board the same way `mergeTile()` does.
+	bottomGap := b.tile.GetBottomGap()
+	boardRow := int(b.tileDepth) - int(bottomGap)
+	if b.tileDepth <= bottomGap {
+		boardRow = int(b.tileDepth)
+	}
+	for row := int(TileSize) - int(bottomGap) - 1; (row >= 0) && (boardRow >= 0); row-- {
+		var mask BoardRow = blockMask << rShiftBlockBitDiff
+		for col := uint8(0); col < BoardWidth; col++ {
+			if (b.tile.shape[row] & mask) > 0 {
+				cells = append(cells, Cell{Row: uint8(boardRow), Col: col})
+			}
+			mask >>= blockBitSize
+		}
+		boardRow--
+	}
+	return cells
+}
+
 /*
  Calculate the "working grid". This is the board with the current dropping
  tile merged with the remaining tile pieces. This is also the visible component
@@ -456,9 +2197,20 @@ func (b *Board) moveX(direction XDirection) bool {
  @return Working version of the grid.
 */
 func (b Board) calcWorkingGrid() *BoardGrid {
+	return b.mergeTile(b.grid)
+}
+
+/*
+ Merges the current dropping tile into a copy of a grid.
+
+ @param grid Grid to merge the tile into.
+
+ @return Copy of the grid, with the tile merged in.
+*/
+func (b Board) mergeTile(grid BoardGrid) *BoardGrid {
 	// Work from the bottom of the tile piece to the top of the tile, adding it
 	// into the working copy of the grid.
-	workingGrid := b.grid
+	workingGrid := grid
 	boardIdx := b.tileDepth
 	bottomGap := b.tile.GetBottomGap()
 	// Take the gap at the bottom of the tile into account only if we won't
@@ -0,0 +1,1075 @@
+/*
+ * File:        board_test.go
+ *
+ * Author:      Schuyler Martin <schuylermartin45@gmail.com>
+ *
+ * Description: Tests for the board, and helpers shared by the model's tests.
+ */
+package model
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+/***** Tests *****/
+
+/*
+ Only the filled cells of a partially filled row are set in its mask.
+*/
+func TestRowFillMaskPartialRow(t *testing.T) {
+	b := newTestBoard(t,
+		"I.T......Z",
+		"IIIIIIIIII",
+	)
+	cases := map[uint8]uint32{
+		BoardHeight - 3: 0,
+		BoardHeight - 2: 0b1000000101,
+		BoardHeight - 1: 0b1111111111,
+		BoardHeight:     0,
+	}
+	for row, expected := range cases {
+		if mask := b.RowFillMask(row); mask != expected {
+			t.Errorf("row %d has mask %b, expected %b", row, mask, expected)
+		}
+	}
+}
+
+/*
+ A resting tile waits out the lock delay, and moving it restarts the wait until
+ it runs out of restarts. Then it locks, even though it can still rotate.
+*/
+func TestLockDelayResetCap(t *testing.T) {
+	const step = 100 * time.Millisecond
+	b := newTestBoard(t)
+	b.SetLockDelay(5 * step)
+	spawnTile(t, b, Cyan)
+	for b.Apply(ActionDown) {
+	}
+	// Every rotation restarts the wait, so the tile keeps resting
+	for i := uint8(0); i < MaxLockResets; i++ {
+		b.Tick(step)
+		if _, _, ok := b.GetActiveTile(); !ok {
+			t.Fatalf("tile locked after %d restarts", i)
+		}
+		if !b.Rotate() {
+			t.Fatal("tile did not rotate")
+		}
+	}
+	// Out of restarts, the wait runs out even though the tile still rotates
+	for i := 0; i < 4; i++ {
+		b.Tick(step)
+		if !b.Rotate() {
+			t.Fatalf("tile did not rotate %d ticks after running out of restarts", i)
+		}
+	}
+	b.Tick(step)
+	if b.LastLockedCells() == nil {
+		t.Fatal("tile did not lock after running out of restarts")
+	}
+}
+
+/*
+ A resting tile locks once it has rested for the maximum time, even if the lock
+ delay is longer and the tile still has restarts left.
+*/
+func TestLockDelayRestingTimeout(t *testing.T) {
+	const step = MaxRestingTime / 5
+	b := newTestBoard(t)
+	b.SetLockDelay(2 * MaxRestingTime)
+	spawnTile(t, b, Cyan)
+	for b.Apply(ActionDown) {
+	}
+	for i := 0; i < 4; i++ {
+		b.Tick(step)
+		b.Rotate()
+		if b.LastLockedCells() != nil {
+			t.Fatalf("tile locked after resting for %v", time.Duration(i+1)*step)
+		}
+	}
+	b.Tick(step)
+	if b.LastLockedCells() == nil {
+		t.Fatal("tile did not lock after resting for the maximum time")
+	}
+}
+
+/*
+ A hard drop does not wait out the lock delay.
+*/
+func TestLockDelayHardDrop(t *testing.T) {
+	b := newTestBoard(t)
+	b.SetLockDelay(time.Second)
+	spawnTile(t, b, Cyan)
+	b.Apply(ActionFastDown)
+	b.Tick(time.Millisecond)
+	if b.LastLockedCells() == nil {
+		t.Fatal("hard dropped tile did not lock")
+	}
+}
+
+/*
+ Every row soft dropped scores a display point, without counting towards the
+ level. Rows the tile falls on its own don't score.
+*/
+func TestSoftDropPoints(t *testing.T) {
+	b := newTestBoard(t)
+	b.SetSpawnGraceEnabled(false)
+	spawnTile(t, b, Cyan)
+	for i := 0; i < 3; i++ {
+		if !b.Apply(ActionDown) {
+			t.Fatal("tile did not soft drop")
+		}
+	}
+	b.Next()
+	if b.GetDropPoints() != 3 {
+		t.Errorf("soft dropping scored %d points, expected 3", b.GetDropPoints())
+	}
+	if b.GetDisplayScore() != "00000003" {
+		t.Errorf("displayed score is %s, expected 00000003", b.GetDisplayScore())
+	}
+	if (b.GetScore() != 0) || (b.GetLevel() != 0) {
+		t.Errorf("soft dropping changed the base score to %d and level to %d", b.GetScore(), b.GetLevel())
+	}
+}
+
+/*
+ Restoring a snapshot undoes every move made since, even ones that locked tiles
+ and cleared rows.
+*/
+func TestRestoreSnapshot(t *testing.T) {
+	b := newTestBoard(t,
+		"I.........",
+		"IIIIIIIII.",
+	)
+	spawnTile(t, b, Grey)
+	original := b.Clone()
+	state := b.Snapshot()
+	b.Apply(ActionLeft)
+	b.Apply(ActionRotate)
+	b.Apply(ActionDown)
+	b.Apply(ActionFastDown)
+	b.Next()
+	dropTile(t, b, Red, 9)
+	if b.Equal(original) {
+		t.Fatal("moves did not change the board")
+	}
+	b.Restore(state)
+	if b.Snapshot() != state {
+		t.Error("restored board does not match the snapshot")
+	}
+	if !b.Equal(original) {
+		t.Error("restored board does not equal the board before the moves")
+	}
+}
+
+/*
+ With the spawn grace, a new tile waits a tick at the top of the board before
+ falling. Without it, the tile falls a row on the tick it spawns. Either way, the
+ tile's depth stays in sync with the rows its blocks are drawn on.
+*/
+func TestSpawnGrace(t *testing.T) {
+	cases := map[bool]uint8{true: 0, false: 1}
+	for grace, topRow := range cases {
+		b := newTestBoard(t)
+		b.SetSpawnGraceEnabled(grace)
+		for _, color := range []TileColor{Red, Yellow, Cyan} {
+			spawnTile(t, b, color)
+			for i := uint8(0); i < 3; i++ {
+				tile, depth, _ := b.GetActiveTile()
+				cells := b.tileCells()
+				minRow, maxRow := BoardHeight, uint8(0)
+				for _, cell := range cells {
+					if cell.Row < minRow {
+						minRow = cell.Row
+					}
+					if cell.Row > maxRow {
+						maxRow = cell.Row
+					}
+				}
+				if minRow != topRow+i {
+					t.Errorf("grace %v: tile %d is drawn from row %d after %d ticks, expected %d", grace, color, minRow, i, topRow+i)
+				}
+				if maxRow != depth-tile.GetBottomGap() {
+					t.Errorf("grace %v: tile %d is drawn down to row %d at depth %d", grace, color, maxRow, depth)
+				}
+				b.Next()
+			}
+			b.Apply(ActionFastDown)
+			b.Next()
+		}
+	}
+}
+
+/*
+ Every tile spawns with its top row of blocks flush against the top of the
+ board, whatever the gap above them in the tile.
+*/
+func TestSpawnFlush(t *testing.T) {
+	for _, color := range []TileColor{Blue, Cyan, Grey, Yellow, Green, Violet, Red} {
+		b := newTestBoard(t)
+		spawnTile(t, b, color)
+		topRow := BoardHeight
+		for _, cell := range b.tileCells() {
+			if cell.Row < topRow {
+				topRow = cell.Row
+			}
+		}
+		if topRow != 0 {
+			t.Errorf("tile %d spawned with its top row of blocks on row %d", color, topRow)
+		}
+	}
+}
+
+/*
+ Every tile is first seen in the middle of the top of the board, in its starting
+ orientation.
+*/
+func TestSpawnPosition(t *testing.T) {
+	cases := map[TileColor][]Cell{
+		Blue:   {{0, 4}, {0, 5}, {1, 3}, {1, 4}},
+		Cyan:   {{0, 4}, {0, 5}, {1, 4}, {1, 5}},
+		Grey:   {{0, 4}, {1, 3}, {1, 4}, {1, 5}},
+		Yellow: {{0, 4}, {1, 4}, {2, 4}, {2, 5}},
+		Green:  {{0, 3}, {0, 4}, {1, 4}, {1, 5}},
+		Violet: {{0, 5}, {1, 5}, {2, 4}, {2, 5}},
+		Red:    {{0, 5}, {1, 5}, {2, 5}, {3, 5}},
+	}
+	for color, expected := range cases {
+		b := newTestBoard(t)
+		spawnTile(t, b, color)
+		var visible []Cell
+		for row, blocks := range b.Current() {
+			for col := uint8(0); col < BoardWidth; col++ {
+				if getBlock(blocks, col) == color {
+					visible = append(visible, Cell{Row: uint8(row), Col: col})
+				}
+			}
+		}
+		if len(visible) != len(expected) {
+			t.Fatalf("tile %d is first seen at %v, expected %v", color, visible, expected)
+		}
+		for i := range expected {
+			if visible[i] != expected[i] {
+				t.Fatalf("tile %d is first seen at %v, expected %v", color, visible, expected)
+			}
+		}
+	}
+}
+
+/*
+ The cells of the last locked tile are its footprint where it locked, and are
+ forgotten when the next tile spawns.
+*/
+func TestLastLockedCells(t *testing.T) {
+	b := newTestBoard(t)
+	spawnTile(t, b, Yellow)
+	b.Apply(ActionLeft)
+	b.Apply(ActionFastDown)
+	footprint := b.tileCells()
+	b.Next()
+	locked := b.LastLockedCells()
+	if len(locked) != len(footprint) {
+		t.Fatalf("locked cells are %v, expected %v", locked, footprint)
+	}
+	for i := range footprint {
+		if locked[i] != footprint[i] {
+			t.Fatalf("locked cells are %v, expected %v", locked, footprint)
+		}
+		if color := getBlock(b.grid[locked[i].Row], locked[i].Col); color != Yellow {
+			t.Errorf("locked cell %v is %d, expected %d", locked[i], color, Yellow)
+		}
+	}
+	checkBottomRows(t, b,
+		"...L......",
+		"...L......",
+		"...LL.....",
+	)
+	spawnTile(t, b, Cyan)
+	if b.LastLockedCells() != nil {
+		t.Error("locked cells were kept after the next spawn")
+	}
+}
+
+/*
+ A tile falls the hard drop distance when it is hard dropped, landing on the
+ floor or on the stack.
+*/
+func TestHardDropDistance(t *testing.T) {
+	cases := map[string][]string{
+		"floor": nil,
+		"stack": {
+			"....I.....",
+			"....I.....",
+			"IIIII....I",
+		},
+	}
+	for name, rows := range cases {
+		b := newTestBoard(t, rows...)
+		spawnTile(t, b, Cyan)
+		tile, depth, _ := b.GetActiveTile()
+		distance := b.HardDropDistance()
+		// The square's bottom row of blocks lands on the floor, or on the row
+		// above the stack
+		landingRow := BoardHeight - uint8(len(rows)) - 1
+		if expected := landingRow + tile.GetBottomGap() - depth; distance != expected {
+			t.Errorf("%s: hard drop distance is %d, expected %d", name, distance, expected)
+		}
+		b.Apply(ActionFastDown)
+		if _, landed, _ := b.GetActiveTile(); landed != depth+distance {
+			t.Errorf("%s: tile landed at depth %d, expected %d", name, landed, depth+distance)
+		}
+		if b.HardDropDistance() != 0 {
+			t.Errorf("%s: landed tile can still fall %d rows", name, b.HardDropDistance())
+		}
+	}
+}
+
+/*
+ The level goes up every ten lines cleared, no matter how many points the clears
+ score.
+*/
+func TestLevelFromLines(t *testing.T) {
+	rows := append([]string{"I........."}, strings.Split(strings.Repeat("IIIIIIIII.\n", 10), "\n")[:10]...)
+	b := newTestBoard(t, rows...)
+	b.SetStartLevel(2)
+	// The first two drops clear four rows each, the last clears the final two
+	for _, lines := range []uint16{4, 8, 10} {
+		dropTile(t, b, Red, 9)
+		if b.GetLines() != lines {
+			t.Fatalf("cleared %d lines, expected %d", b.GetLines(), lines)
+		}
+		expected := uint8(2)
+		if lines >= 10 {
+			expected = 3
+		}
+		if b.GetLevel() != expected {
+			t.Fatalf("level is %d after %d lines and %d points, expected %d", b.GetLevel(), lines, b.GetScore(), expected)
+		}
+	}
+}
+
+/*
+ The level, and the speed of gravity with it, stops rising at the max level.
+*/
+func TestMaxLevel(t *testing.T) {
+	b := newTestBoard(t,
+		"I.........",
+		"IIIIIIIII.",
+		"IIIIIIIII.",
+	)
+	b.SetMaxLevel(3)
+	// The clear reaches level 5
+	b.lines = 48
+	dropTile(t, b, Red, 9)
+	if b.GetLevel() != 3 {
+		t.Fatalf("level is %d, expected the max level of 3", b.GetLevel())
+	}
+	interval := b.GetGravityInterval()
+	b.SetMaxLevel(0)
+	if b.GetLevel() != 5 {
+		t.Errorf("uncapped level is %d, expected 5", b.GetLevel())
+	}
+	if b.GetGravityInterval() >= interval {
+		t.Errorf("gravity interval of %v was not capped at %v", b.GetGravityInterval(), interval)
+	}
+}
+
+/*
+ Boards in play are valid, and every kind of corruption is caught.
+*/
+func TestValidate(t *testing.T) {
+	b := newTestBoard(t,
+		"I.T......Z",
+		"IIIIIIIII.",
+	)
+	spawnTile(t, b, Red)
+	if err := b.Validate(); err != nil {
+		t.Fatalf("valid board failed validation: %v", err)
+	}
+	corruptions := map[string]func(b *Board){
+		"padding": func(b *Board) {
+			b.grid[3] &^= maskRow2BitPad
+		},
+		"phantom row": func(b *Board) {
+			b.grid[BoardHeight] = maskRow2BitPad
+		},
+		"tile padding": func(b *Board) {
+			b.tile.shape[0] |= maskRow2BitPad
+		},
+		"tile depth": func(b *Board) {
+			b.tileDepth = BoardHeight + TileSize
+		},
+	}
+	for name, corrupt := range corruptions {
+		corrupted := b.Clone()
+		corrupt(corrupted)
+		if err := corrupted.Validate(); err == nil {
+			t.Errorf("board with corrupt %s passed validation", name)
+		}
+	}
+	if err := b.Validate(); err != nil {
+		t.Errorf("corrupting clones invalidated the original: %v", err)
+	}
+}
+
+/*
+ With gravity off, ticks leave the tile where it is, but it can still be moved
+ and dropped.
+*/
+func TestGravityDisabled(t *testing.T) {
+	b := newTestBoard(t)
+	spawnTile(t, b, Grey)
+	b.SetGravityEnabled(false)
+	_, depth, _ := b.GetActiveTile()
+	for i := 0; i < 10; i++ {
+		b.Tick(b.GetGravityInterval())
+	}
+	if _, ticked, _ := b.GetActiveTile(); ticked != depth {
+		t.Fatalf("tile fell from depth %d to %d with gravity off", depth, ticked)
+	}
+	if !b.Apply(ActionDown) {
+		t.Fatal("tile did not soft drop with gravity off")
+	}
+	if _, dropped, _ := b.GetActiveTile(); dropped != depth+1 {
+		t.Errorf("soft dropped tile is at depth %d, expected %d", dropped, depth+1)
+	}
+	b.SetGravityEnabled(true)
+	b.Tick(b.GetGravityInterval())
+	if _, ticked, _ := b.GetActiveTile(); ticked != depth+2 {
+		t.Errorf("tile is at depth %d with gravity back on, expected %d", ticked, depth+2)
+	}
+}
+
+/*
+ The drop path runs from the tile down to where it lands, on the floor or on top
+ of the stack, without covering the stack.
+*/
+func TestDropPathCells(t *testing.T) {
+	cases := map[string][]string{
+		"floor": nil,
+		"stack": {
+			".....I....",
+			".....I....",
+			"IIIIII....",
+		},
+	}
+	for name, rows := range cases {
+		b := newTestBoard(t, rows...)
+		spawnTile(t, b, Red)
+		path := b.DropPathCells()
+		// The pipe is one column wide, so the path is every row above the stack
+		landingRow := BoardHeight - uint8(len(rows)) - 1
+		if len(path) != int(landingRow)+1 {
+			t.Fatalf("%s: path is %v, expected rows 0 to %d", name, path, landingRow)
+		}
+		for i, cell := range path {
+			if (cell.Col != 5) || (cell.Row > landingRow) {
+				t.Errorf("%s: path passes through %v", name, cell)
+			}
+			for _, other := range path[:i] {
+				if other == cell {
+					t.Errorf("%s: path passes through %v twice", name, cell)
+				}
+			}
+		}
+	}
+}
+
+/*
+ Filled rows are cleared for exactly the line clear delay, before they are
+ removed.
+*/
+func TestLineClearDelay(t *testing.T) {
+	const (
+		delay = 100 * time.Millisecond
+		step  = 10 * time.Millisecond
+	)
+	b := newTestBoard(t,
+		"I.........",
+		"IIIIIIIII.",
+	)
+	b.SetDelays(delay, 0)
+	spawnTile(t, b, Red)
+	b.DropInColumn(9)
+	b.Tick(b.GetGravityInterval())
+	if !b.IsClearing() {
+		t.Fatal("locking the tile did not start clearing")
+	}
+	if rows := b.ClearingRows(); (len(rows) != 1) || (rows[0] != BoardHeight-1) {
+		t.Fatalf("clearing rows %v, expected [%d]", rows, BoardHeight-1)
+	}
+	for elapsed := step; elapsed < delay; elapsed += step {
+		b.Tick(step)
+		if !b.IsClearing() {
+			t.Fatalf("rows were cleared after %v", elapsed)
+		}
+		if progress := b.ClearProgress(); math.Abs(progress-(float64(elapsed)/float64(delay))) > 1e-9 {
+			t.Errorf("clear is %v done after %v", progress, elapsed)
+		}
+	}
+	b.Tick(step)
+	if b.IsClearing() {
+		t.Fatalf("rows are still clearing after %v", delay)
+	}
+	if b.GetLines() != 1 {
+		t.Errorf("cleared %d lines, expected 1", b.GetLines())
+	}
+}
+
+/*
+ A board equals itself after a round-trip through the text encoding, and equals
+ its clone until either one plays on.
+*/
+func TestEqual(t *testing.T) {
+	b := newTestBoard(t,
+		"I.T......Z",
+		"IIIIIIIII.",
+	)
+	decoded, err := ImportText(b.ExportText())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.Equal(b) || !b.Equal(decoded) {
+		t.Error("board does not equal itself after a round-trip through text")
+	}
+
+	spawnTile(t, b, Grey)
+	clone := b.Clone()
+	if !clone.Equal(b) || !b.Equal(clone) {
+		t.Fatal("board does not equal its clone")
+	}
+	clone.Apply(ActionLeft)
+	if clone.Equal(b) {
+		t.Error("board equals its clone after the clone moved")
+	}
+	if b.Equal(nil) {
+		t.Error("board equals nil")
+	}
+}
+
+/*
+ Boards that differ only in state that isn't on the grid, like the rotation
+ state of the tile, pending garbage, or the zone, neither equal each other nor
+ hash the same.
+*/
+func TestEqualHiddenState(t *testing.T) {
+	changes := map[string]func(b *Board){
+		"rotation state":  func(b *Board) { b.rotationState = 2 },
+		"pending garbage": func(b *Board) { b.pendingGarbage = 1 },
+		"zone":            func(b *Board) { b.inZone = true },
+		"zone lines":      func(b *Board) { b.zoneLines = 1 },
+	}
+	b := newTestBoard(t, "IIIIIIIII.")
+	spawnTile(t, b, Grey)
+	for name, change := range changes {
+		changed := b.Clone()
+		change(changed)
+		if changed.Equal(b) || b.Equal(changed) {
+			t.Errorf("boards with different %s are equal", name)
+		}
+		if changed.Checksum() == b.Checksum() {
+			t.Errorf("boards with different %s hash the same", name)
+		}
+	}
+}
+
+/*
+ With soft drop locking, a tile that a soft drop lands locks on the next tick,
+ even with a lock delay. Without it, the landed tile can still slide.
+*/
+func TestSoftDropLock(t *testing.T) {
+	type setup struct {
+		softDropLock bool
+		lockDelay    time.Duration
+	}
+	cases := map[setup]bool{
+		{false, 0}:                      false,
+		{true, 0}:                       true,
+		{false, 500 * time.Millisecond}: false,
+		{true, 500 * time.Millisecond}:  true,
+	}
+	for options, locks := range cases {
+		b := newTestBoard(t)
+		b.SetSoftDropLock(options.softDropLock)
+		b.SetLockDelay(options.lockDelay)
+		spawnTile(t, b, Cyan)
+		for b.Apply(ActionDown) {
+		}
+		b.Tick(time.Millisecond)
+		if locked := (b.LastLockedCells() != nil); locked != locks {
+			t.Errorf("%+v: tile locked %v, expected %v", options, locked, locks)
+			continue
+		}
+		if !locks && !b.Apply(ActionLeft) {
+			t.Errorf("%+v: landed tile did not slide", options)
+		}
+	}
+}
+
+/*
+ Every tick is a frame, however much time passes in it.
+*/
+func TestFrame(t *testing.T) {
+	b := newTestBoard(t)
+	if b.Frame() != 0 {
+		t.Fatalf("new board is on frame %d", b.Frame())
+	}
+	steps := []time.Duration{0, time.Millisecond, b.GetGravityInterval(), 10 * b.GetGravityInterval()}
+	for n := 1; n <= 20; n++ {
+		b.Tick(steps[n%len(steps)])
+		if b.Frame() != uint64(n) {
+			t.Fatalf("board is on frame %d after %d ticks", b.Frame(), n)
+		}
+	}
+	b.Next()
+	if b.Frame() != 20 {
+		t.Errorf("moving to the next iteration changed the frame to %d", b.Frame())
+	}
+}
+
+/*
+ The cleared cells report the colors the cleared rows had before they were
+ cleared, and are forgotten on the next lock.
+*/
+func TestLastClearedCells(t *testing.T) {
+	b := newTestBoard(t,
+		"I.........",
+		"SOTLZJISO.",
+		"JJZZTTLLO.",
+	)
+	spawnTile(t, b, Red)
+	b.DropInColumn(9)
+	working := b.calcWorkingGrid()
+	b.Next()
+	cleared := b.LastClearedCells()
+	if len(cleared) != 2*int(BoardWidth) {
+		t.Fatalf("cleared %d cells, expected %d", len(cleared), 2*BoardWidth)
+	}
+	for _, cell := range cleared {
+		if (cell.Row < BoardHeight-2) || (cell.Color != getBlock(working[cell.Row], cell.Col)) {
+			t.Errorf("cleared %+v, expected color %d", cell, getBlock(working[cell.Row], cell.Col))
+		}
+	}
+	dropTile(t, b, Cyan, 4)
+	if b.LastClearedCells() != nil {
+		t.Error("cleared cells were kept after a lock that cleared nothing")
+	}
+}
+
+/*
+ Moving several columns stops at the wall or the stack, reporting how far the
+ tile got.
+*/
+func TestMoveN(t *testing.T) {
+	b := newTestBoard(t,
+		"..I.......",
+		"..I.......",
+		"..I.......",
+		"..I.......",
+	)
+	spawnTile(t, b, Red)
+	if moved := b.MoveRightN(8); moved != 4 {
+		t.Errorf("moved right %d columns, expected to stop at the wall after 4", moved)
+	}
+	if moved := b.MoveRightN(2); moved != 0 {
+		t.Errorf("moved right %d columns into the wall", moved)
+	}
+	if moved := b.MoveLeftN(12); moved != 9 {
+		t.Errorf("moved left %d columns, expected to stop at the wall after 9", moved)
+	}
+	// On the floor, the stack is in the way
+	b.MoveRightN(5)
+	for b.Apply(ActionDown) {
+	}
+	if moved := b.MoveLeftN(5); moved != 2 {
+		t.Errorf("moved left %d columns, expected to stop at the stack after 2", moved)
+	}
+	tile, _, _ := b.GetActiveTile()
+	if _, leftCol, _, _ := tile.BoundingBox(); leftCol != 3 {
+		t.Errorf("tile stopped in column %d, expected 3", leftCol)
+	}
+}
+
+/*
+ With instant gravity, a tile lands as soon as it spawns, and falls off of
+ ledges as soon as it is slid off of them.
+*/
+func TestInstantGravity(t *testing.T) {
+	rows := []string{
+		"....III...",
+		"....III...",
+		"IIIIIII...",
+	}
+	reference := newTestBoard(t, rows...)
+	spawnTile(t, reference, Red)
+	_, spawnDepth, _ := reference.GetActiveTile()
+	landing := spawnDepth + reference.HardDropDistance()
+
+	b := newTestBoard(t, rows...)
+	b.SetInstantGravity(true)
+	spawnTile(t, b, Red)
+	if _, depth, _ := b.GetActiveTile(); depth != landing {
+		t.Fatalf("tile spawned at depth %d, expected to land at %d", depth, landing)
+	}
+	if b.HardDropDistance() != 0 {
+		t.Errorf("tile can still fall %d rows after spawning", b.HardDropDistance())
+	}
+	// Sliding off of the ledge drops the tile on the next tick
+	b.MoveRightN(2)
+	b.Tick(time.Millisecond)
+	if _, depth, _ := b.GetActiveTile(); (depth <= landing) || (b.HardDropDistance() != 0) {
+		t.Errorf("tile slid off of the ledge stayed at depth %d", depth)
+	}
+}
+
+/*
+ Boards in the same state hash the same, and changing a single cell changes the
+ hash.
+*/
+func TestChecksum(t *testing.T) {
+	rows := []string{
+		"I.T......Z",
+		"IIIIIIIII.",
+	}
+	b := newTestBoard(t, rows...)
+	other := newTestBoard(t, rows...)
+	if b.Checksum() != other.Checksum() {
+		t.Fatal("identical boards hash differently")
+	}
+	// The next tile is hashed too, so every board picks the same tiles
+	b.SetSeedFromString("checksum")
+	other.SetSeedFromString("checksum")
+	spawnTile(t, b, Grey)
+	spawnTile(t, other, Grey)
+	if b.Checksum() != other.Checksum() {
+		t.Fatal("identical boards with the same tile hash differently")
+	}
+	changed := newTestBoard(t,
+		"I.T......Z",
+		"IIIIIIII.I",
+	)
+	changed.SetSeedFromString("checksum")
+	spawnTile(t, changed, Grey)
+	if changed.Checksum() == b.Checksum() {
+		t.Error("boards a cell apart hash the same")
+	}
+	other.grid[BoardHeight-2] = setBlock(other.grid[BoardHeight-2], 0, Cyan)
+	if other.Checksum() == b.Checksum() {
+		t.Error("changing the color of a cell did not change the hash")
+	}
+}
+
+/*
+ Shifting the stack down discards the bottom rows and leaves the tile and the
+ phantom row in place. Shifting by more than the board empties it.
+*/
+func TestShiftDown(t *testing.T) {
+	b := newTestBoard(t,
+		"....T.....",
+		"I.T......Z",
+		"IIIIIIIII.",
+		"OOOOO.OOOO",
+	)
+	spawnTile(t, b, Red)
+	_, depth, _ := b.GetActiveTile()
+	b.ShiftDown(2)
+	checkBottomRows(t, b,
+		"..........",
+		"..........",
+		"....T.....",
+		"I.T......Z",
+	)
+	if _, shifted, _ := b.GetActiveTile(); shifted != depth {
+		t.Errorf("tile moved from depth %d to %d", depth, shifted)
+	}
+	if err := b.Validate(); err != nil {
+		t.Error(err)
+	}
+	b.ShiftDown(BoardHeight + 1)
+	if !isGridEmpty(b.grid) {
+		t.Errorf("board is not empty after shifting every row off:\n%s", b.ExportText())
+	}
+}
+
+/*
+ Mirroring flips the stack and the dropping tile from left to right, and
+ mirroring again restores them.
+*/
+func TestMirror(t *testing.T) {
+	b := newTestBoard(t,
+		"I.T......Z",
+		"IIIIIOOLL.",
+	)
+	spawnTile(t, b, Yellow)
+	original := b.Clone()
+	cells := b.tileCells()
+	b.Mirror()
+	checkBottomRows(t, b,
+		"Z......T.I",
+		".LLOOIIIII",
+	)
+	mirrored := make(map[Cell]bool)
+	for _, cell := range b.tileCells() {
+		mirrored[cell] = true
+	}
+	for _, cell := range cells {
+		if !mirrored[Cell{Row: cell.Row, Col: BoardWidth - 1 - cell.Col}] || (len(mirrored) != len(cells)) {
+			t.Fatalf("mirrored tile covers %v, expected the mirror of %v", b.tileCells(), cells)
+		}
+	}
+	if color, _ := b.ActiveTileColor(); color != Yellow {
+		t.Errorf("mirrored tile is %d, expected %d", color, Yellow)
+	}
+	b.Mirror()
+	if !b.Equal(original) {
+		t.Error("mirroring twice did not restore the board")
+	}
+}
+
+/*
+ Only the cells that changed since the previous capture are drawn, and every
+ cell is drawn without a previous capture.
+*/
+func TestRenderRegion(t *testing.T) {
+	b := newTestBoard(t,
+		"I.T......Z",
+	)
+	spawnTile(t, b, Red)
+	prev := append([]BoardRow(nil), b.Current()...)
+	drawn := make(map[Cell]TileColor)
+	draw := func(row uint8, col uint8, isEOL bool, color TileColor) {
+		drawn[Cell{Row: row, Col: col}] = color
+	}
+	b.RenderRegion(prev, draw)
+	if len(drawn) != 0 {
+		t.Fatalf("drew %v, expected nothing to have changed", drawn)
+	}
+	b.MoveLeft()
+	b.RenderRegion(prev, draw)
+	expected := make(map[Cell]TileColor)
+	for row := uint8(0); row < TileSize; row++ {
+		expected[Cell{Row: row, Col: 4}] = Red
+		expected[Cell{Row: row, Col: 5}] = Transparent
+	}
+	if len(drawn) != len(expected) {
+		t.Fatalf("drew %v, expected %v", drawn, expected)
+	}
+	for cell, color := range expected {
+		if actual, ok := drawn[cell]; !ok || (actual != color) {
+			t.Errorf("drew %v as %d, expected %d", cell, actual, color)
+		}
+	}
+	drawn = make(map[Cell]TileColor)
+	b.RenderRegion(nil, draw)
+	if len(drawn) != int(BoardHeight)*int(BoardWidth) {
+		t.Errorf("drew %d cells without a previous capture, expected %d", len(drawn), int(BoardHeight)*int(BoardWidth))
+	}
+}
+
+/*
+ Every cell of the placed blocks is visited once, leaving out the dropping tile,
+ until the callback stops the iteration.
+*/
+func TestForEachCell(t *testing.T) {
+	b := newTestBoard(t,
+		"I.T......Z",
+		"IIIIIIIII.",
+	)
+	spawnTile(t, b, Red)
+	visited, filled := 0, 0
+	b.ForEachCell(func(row uint8, col uint8, color TileColor) bool {
+		visited++
+		if color != Transparent {
+			filled++
+		}
+		return true
+	})
+	if visited != int(BoardHeight)*int(BoardWidth) {
+		t.Errorf("visited %d cells, expected %d", visited, int(BoardHeight)*int(BoardWidth))
+	}
+	if filled != 12 {
+		t.Errorf("visited %d filled cells, expected 12", filled)
+	}
+	visited = 0
+	b.ForEachCell(func(row uint8, col uint8, color TileColor) bool {
+		visited++
+		return visited < 5
+	})
+	if visited != 5 {
+		t.Errorf("visited %d cells after stopping at 5", visited)
+	}
+}
+
+/*
+ Every cleared row cancels a row of queued garbage, and the rest rises when the
+ next tile spawns.
+*/
+func TestClearsCancelGarbage(t *testing.T) {
+	b := newTestBoard(t,
+		"I.........",
+		"IIIIIIIII.",
+		"IIIIIIIII.",
+	)
+	spawnTile(t, b, Red)
+	// Garbage sent while the tile drops waits for it to lock
+	b.QueueGarbage(3)
+	b.DropInColumn(9)
+	b.Next()
+	if b.GetLines() != 2 {
+		t.Fatalf("cleared %d lines, expected 2", b.GetLines())
+	}
+	if b.PendingGarbage() != 1 {
+		t.Fatalf("%d rows of garbage are queued, expected 1", b.PendingGarbage())
+	}
+	spawnTile(t, b, Cyan)
+	if b.PendingGarbage() != 0 {
+		t.Errorf("%d rows of garbage are still queued after the spawn", b.PendingGarbage())
+	}
+	rows := boardRows(b)
+	if garbage := rows[len(rows)-1]; strings.Count(garbage, ".") != 1 {
+		t.Errorf("bottom row is %s, expected a row of garbage", garbage)
+	}
+
+	// Clearing more rows than are queued cancels all of the garbage
+	b = newTestBoard(t,
+		"I.........",
+		"IIIIIIIII.",
+		"IIIIIIIII.",
+	)
+	spawnTile(t, b, Red)
+	b.QueueGarbage(1)
+	b.DropInColumn(9)
+	b.Next()
+	if b.PendingGarbage() != 0 {
+		t.Errorf("%d rows of garbage are queued, expected none", b.PendingGarbage())
+	}
+}
+
+/*
+ Clearing the board empties it, and keeps the score, level, and lines, so play
+ carries on.
+*/
+func TestClear(t *testing.T) {
+	b := newTestBoard(t,
+		"I.........",
+		"I.T..Z....",
+		"IIIIIIIII.",
+	)
+	dropTile(t, b, Red, 9)
+	spawnTile(t, b, Cyan)
+	score, level, lines := b.GetScore(), b.GetLevel(), b.GetLines()
+	if lines == 0 {
+		t.Fatal("drop did not clear a line")
+	}
+	b.Clear()
+	if !isGridEmpty(b.grid) {
+		t.Errorf("board is not empty after clearing it:\n%s", b.ExportText())
+	}
+	if _, _, ok := b.GetActiveTile(); ok {
+		t.Error("tile is still dropping after clearing the board")
+	}
+	if (b.GetScore() != score) || (b.GetLevel() != level) || (b.GetLines() != lines) {
+		t.Errorf("clearing changed the score, level, and lines to %d, %d, and %d, expected %d, %d, and %d",
+			b.GetScore(), b.GetLevel(), b.GetLines(), score, level, lines)
+	}
+	if err := b.Validate(); err != nil {
+		t.Error(err)
+	}
+	// Play carries on with the next tile
+	spawnTile(t, b, Red)
+}
+
+/***** Internal Functions *****/
+
+/*
+ Constructs a board from rows of the text encoding, stacked on the floor. Rows
+ above them are empty. Clears and spawns happen without delay, so tests don't
+ have to wait on them.
+
+ @param t    Test the board is for.
+ @param rows Bottom rows of the board, top to bottom.
+
+ @return The board.
+*/
+func newTestBoard(t *testing.T, rows ...string) *Board {
+	t.Helper()
+	text := "score 0 level 0 lines 0\n" +
+		strings.Repeat(strings.Repeat(".", int(BoardWidth))+"\n", int(BoardHeight)-len(rows)) +
+		strings.Join(rows, "\n")
+	b, err := ImportText(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.SetDelays(0, 0)
+	return b
+}
+
+/*
+ Gets the rows of a board's placed blocks in the text encoding.
+
+ @param b Board to encode.
+
+ @return Every row of the board, top to bottom.
+*/
+func boardRows(b *Board) []string {
+	return strings.Split(strings.TrimSpace(b.ExportText()), "\n")[1:]
+}
+
+/*
+ Checks the bottom rows of a board's placed blocks.
+
+ @param t    Test doing the check.
+ @param b    Board to check.
+ @param rows Expected bottom rows of the board, top to bottom.
+*/
+func checkBottomRows(t *testing.T, b *Board, rows ...string) {
+	t.Helper()
+	actual := boardRows(b)
+	actual = actual[len(actual)-len(rows):]
+	for i := range rows {
+		if actual[i] != rows[i] {
+			t.Fatalf("bottom rows are\n%s\nexpected\n%s", strings.Join(actual, "\n"), strings.Join(rows, "\n"))
+		}
+	}
+}
+
+/*
+ Spawns a specific tile at the top of the board.
+
+ @param t     Test doing the spawn.
+ @param b     Board to spawn the tile on. No tile may be dropping.
+ @param color Identifying color of the tile to spawn.
+*/
+func spawnTile(t *testing.T, b *Board, color TileColor) {
+	t.Helper()
+	if err := b.ForceSpawn(color); err != nil {
+		t.Fatal(err)
+	}
+	b.Next()
+	if active, ok := b.ActiveTileColor(); !ok || (active != color) {
+		t.Fatalf("spawned tile %d, expected %d", active, color)
+	}
+}
+
+/*
+ Spawns a specific tile, drops it with its left-most block in a column, and
+ locks it.
+
+ @param t     Test doing the drop.
+ @param b     Board to drop the tile on. No tile may be dropping.
+ @param color Identifying color of the tile to drop.
+ @param col   Column to drop the tile in.
+*/
+func dropTile(t *testing.T, b *Board, color TileColor, col uint8) {
+	t.Helper()
+	spawnTile(t, b, color)
+	if !b.DropInColumn(col) {
+		t.Fatalf("tile %d could not be dropped in column %d", color, col)
+	}
+	b.Next()
+	if _, _, ok := b.GetActiveTile(); ok {
+		t.Fatal("tile did not lock")
+	}
+}
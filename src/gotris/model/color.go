@@ -0,0 +1,41 @@
+/*
+ * File:        color.go
+ *
+ * Author:      Schuyler Martin <schuylermartin45@gmail.com>
+ *
+ * Description: Display colors of tiles, for views that draw with real colors
+ *              instead of terminal color codes.
+ */
+package model
+
+/***** Variables *****/
+
+// Red, green, and blue components of each tile color. These follow the Windows
+// 98 Tetris color scheme the tiles do.
+var tileColorRGB = [Red + 1][3]uint8{
+	Transparent: {0x00, 0x00, 0x00},
+	Blue:        {0x00, 0x00, 0xFF},
+	Cyan:        {0x00, 0xFF, 0xFF},
+	Grey:        {0x80, 0x80, 0x80},
+	Yellow:      {0xFF, 0xFF, 0x00},
+	Green:       {0x00, 0x80, 0x00},
+	Violet:      {0x80, 0x00, 0x80},
+	Red:         {0xFF, 0x00, 0x00},
+}
+
+/***** Methods *****/
+
+/*
+ Get the color as red, green, and blue components, so every view draws tiles
+ with the same colors.
+
+ @return The red, green, and blue components of the color. Unknown colors are
+         black, like `Transparent`.
+*/
+func (c TileColor) RGB() (r uint8, g uint8, b uint8) {
+	if c > Red {
+		c = Transparent
+	}
+	rgb := tileColorRGB[c]
+	return rgb[0], rgb[1], rgb[2]
+}
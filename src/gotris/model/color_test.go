@@ -0,0 +1,40 @@
+/*
+ * File:        color_test.go
+ *
+ * Author:      Schuyler Martin <schuylermartin45@gmail.com>
+ *
+ * Description: Tests for the display colors of tiles.
+ */
+package model
+
+import (
+	"testing"
+)
+
+/***** Tests *****/
+
+/*
+ Every tile color, and the empty color, is drawn with its own RGB color. Unknown
+ colors are drawn like empty cells.
+*/
+func TestTileColorRGB(t *testing.T) {
+	seen := make(map[[3]uint8]TileColor)
+	for color := Transparent; color <= Red; color++ {
+		r, g, b := color.RGB()
+		rgb := [3]uint8{r, g, b}
+		if other, ok := seen[rgb]; ok {
+			t.Errorf("colors %d and %d are both drawn as %v", other, color, rgb)
+		}
+		seen[rgb] = color
+		if (color != Transparent) && (rgb == [3]uint8{}) {
+			t.Errorf("color %d is drawn black, like an empty cell", color)
+		}
+	}
+	if len(seen) != int(Red)+1 {
+		t.Errorf("%d distinct colors, expected %d", len(seen), Red+1)
+	}
+	r, g, b := (Red + 1).RGB()
+	if [3]uint8{r, g, b} != [3]uint8{} {
+		t.Errorf("unknown color is drawn as %v, expected black", [3]uint8{r, g, b})
+	}
+}
@@ -0,0 +1,79 @@
+/*
+ * File:        event.go
+ *
+ * Author:      Schuyler Martin <schuylermartin45@gmail.com>
+ *
+ * Description: Events that the board reports to views as the game progresses.
+ */
+package model
+
+/***** Types *****/
+
+// Event describes something notable that happened during the game.
+type Event uint8
+
+// Event enumerations
+const (
+	// The level counter increased
+	EventLevelUp Event = 1
+	// A line clear emptied the entire board
+	EventPerfectClear Event = 2
+	// One or more rows were cleared
+	EventLinesCleared Event = 3
+	// As many rows as a tile is tall were cleared at once, a "Tetris"
+	EventTetris Event = 4
+	// The player moved the tile left or right
+	EventMove Event = 5
+	// The player rotated the tile
+	EventRotate Event = 6
+	// The player moved the tile down a row
+	EventSoftDrop Event = 7
+	// The player dropped the tile to the floor
+	EventHardDrop Event = 8
+	// The dropping tile locked in place
+	EventLock Event = 9
+)
+
+/***** Variables *****/
+
+// Events reported when an action succeeds. Views can play a different sound for
+// each one.
+var actionEvents = map[Action]Event{
+	ActionLeft:     EventMove,
+	ActionRight:    EventMove,
+	ActionDown:     EventSoftDrop,
+	ActionFastDown: EventHardDrop,
+	ActionRotate:   EventRotate,
+}
+
+/*
+ EventHandler is a callback triggered when the board reports an event.
+
+ @param event Event that occurred.
+*/
+type EventHandler func(event Event)
+
+/***** Methods *****/
+
+/*
+ Registers a callback to be triggered whenever an event occurs. Only one
+ callback is kept, registering another replaces the previous one.
+
+ @param handler Callback to trigger on events. Nil stops reporting events.
+*/
+func (b *Board) OnEvent(handler EventHandler) {
+	b.onEvent = handler
+}
+
+/***** Internal Methods *****/
+
+/*
+ Reports an event to the registered callback, if there is one.
+
+ @param event Event that occurred.
+*/
+func (b *Board) fireEvent(event Event) {
+	if b.onEvent != nil {
+		b.onEvent(event)
+	}
+}
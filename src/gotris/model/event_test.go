@@ -0,0 +1,48 @@
+/*
+ * File:        event_test.go
+ *
+ * Author:      Schuyler Martin <schuylermartin45@gmail.com>
+ *
+ * Description: Tests for the events the board reports.
+ */
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+/***** Tests *****/
+
+/*
+ Clearing the 10th line levels up once, and the clears before it don't, even
+ though they score more than ten points.
+*/
+func TestLevelUpEvent(t *testing.T) {
+	rows := append([]string{"I........."}, strings.Split(strings.Repeat("IIIIIIIII.\n", 10), "\n")[:10]...)
+	b := newTestBoard(t, rows...)
+	events := recordEvents(b)
+	// The first two drops clear four rows each, the last clears the final two
+	for i, lines := range []uint16{4, 8, 10} {
+		dropTile(t, b, Red, 9)
+		if b.GetLines() != lines {
+			t.Fatalf("cleared %d lines after drop %d, expected %d", b.GetLines(), i, lines)
+		}
+		levelUps := 0
+		for _, event := range *events {
+			if event == EventLevelUp {
+				levelUps++
+			}
+		}
+		expected := 0
+		if lines >= 10 {
+			expected = 1
+		}
+		if levelUps != expected {
+			t.Fatalf("reported %d level ups after %d lines, expected %d", levelUps, lines, expected)
+		}
+	}
+	if b.GetLevel() != 1 {
+		t.Errorf("level is %d, expected 1", b.GetLevel())
+	}
+}
@@ -0,0 +1,207 @@
+/*
+ * File:        gravity.go
+ *
+ * Author:      Schuyler Martin <schuylermartin45@gmail.com>
+ *
+ * Description: Rules for how blocks fall after rows are cleared.
+ */
+package model
+
+/***** Types *****/
+
+// ClearGravity describes how blocks above a cleared row fall.
+type ClearGravity uint8
+
+// ClearGravity enumerations
+const (
+	// Rows above a cleared row shift down by one row, even if that leaves blocks
+	// floating over a gap.
+	NaiveShift ClearGravity = 0
+	// Groups of connected blocks fall independently until they land on
+	// something. Landing groups can fill more rows, which are cleared in turn.
+	Sticky ClearGravity = 1
+)
+
+/***** Internal Functions *****/
+
+/*
+ Get the color of a single block in a row.
+
+ @param row Row of blocks.
+ @param col Column of the block.
+
+ @return Color of the block. `Transparent` if there is no block.
+*/
+func getBlock(row BoardRow, col uint8) TileColor {
+	return TileColor((row >> (rShiftBlockBitDiff - (blockBitSize * BoardRow(col)))) & blockMask)
+}
+
+/*
+ Sets the color of a single block in a row.
+
+ @param row   Row of blocks.
+ @param col   Column of the block.
+ @param color Color of the block. `Transparent` removes the block.
+
+ @return The row, with the block set.
+*/
+func setBlock(row BoardRow, col uint8, color TileColor) BoardRow {
+	shift := rShiftBlockBitDiff - (blockBitSize * BoardRow(col))
+	row &^= blockMask << shift
+	return row | (BoardRow(color) << shift)
+}
+
+/*
+ Clears filled rows by shifting every row above them down.
+
+ @param grid Grid to clear rows from.
+
+ @return Number of rows cleared.
+*/
+func clearRowsNaive(grid *BoardGrid) uint16 {
+	// Remember that there is a phantom row at the bottom of the board that is
+	// not rendered.
+	numCleared := uint16(0)
+	for row := int8(BoardHeight - 1); row >= 0; row-- {
+		if calcCollisionRow(grid[row]) == maskFullRow {
+			for i := row; i >= 1; i-- {
+				grid[i] = grid[i-1]
+			}
+			// Top row gets wiped clean.
+			grid[0] = maskRow2BitPad
+			// Count the cleared rows.
+			numCleared++
+			// Reset row calculation to run against the same row again.
+			// In the event that multiple rows are cleared at once, this
+			// prevents us from leaving a full row beind.
+			row++
+		}
+	}
+	return numCleared
+}
+
+/*
+ Clears filled rows and lets the remaining groups of connected blocks fall until
+ they land. Falling blocks can fill more rows, which are cleared in turn. Each
+ round of clearing is a link in a chain. Repeats until no filled rows remain.
+
+ @param grid Grid to clear rows from.
+
+ @return Number of rows cleared by each link of the chain, in order. Empty if no
+         rows were cleared.
+*/
+func clearRowsSticky(grid *BoardGrid) []uint16 {
+	var chain []uint16
+	for {
+		cleared := uint16(0)
+		for row := uint8(0); row < BoardHeight; row++ {
+			if calcCollisionRow(grid[row]) == maskFullRow {
+				grid[row] = maskRow2BitPad
+				cleared++
+			}
+		}
+		if cleared == 0 {
+			return chain
+		}
+		chain = append(chain, cleared)
+		// Keep dropping groups until every group has landed, as a landing group
+		// can make room for another one to fall further.
+		for dropGroups(grid) {
+		}
+	}
+}
+
+/*
+ Finds the groups of connected blocks (blocks that touch on a side).
+
+ @param grid Grid to search.
+
+ @return Every group of connected blocks, as a list of cells.
+*/
+func findGroups(grid *BoardGrid) [][]Cell {
+	var groups [][]Cell
+	var seen [BoardHeight][BoardWidth]bool
+	for row := uint8(0); row < BoardHeight; row++ {
+		for col := uint8(0); col < BoardWidth; col++ {
+			if seen[row][col] || (getBlock(grid[row], col) == Transparent) {
+				continue
+			}
+			// Flood fill out from the first block found
+			var group []Cell
+			stack := []Cell{{Row: row, Col: col}}
+			seen[row][col] = true
+			for len(stack) > 0 {
+				cell := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				group = append(group, cell)
+				neighbors := []Cell{
+					{Row: cell.Row - 1, Col: cell.Col},
+					{Row: cell.Row + 1, Col: cell.Col},
+					{Row: cell.Row, Col: cell.Col - 1},
+					{Row: cell.Row, Col: cell.Col + 1},
+				}
+				for _, next := range neighbors {
+					// Out of bounds cells underflow, so one check covers both ends
+					if (next.Row >= BoardHeight) || (next.Col >= BoardWidth) {
+						continue
+					}
+					if seen[next.Row][next.Col] || (getBlock(grid[next.Row], next.Col) == Transparent) {
+						continue
+					}
+					seen[next.Row][next.Col] = true
+					stack = append(stack, next)
+				}
+			}
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+/*
+ Drops every group of connected blocks as far as it can fall.
+
+ @param grid Grid to drop blocks in.
+
+ @return True if any group moved. False if every group has landed.
+*/
+func dropGroups(grid *BoardGrid) bool {
+	moved := false
+	for _, group := range findGroups(grid) {
+		// Lift the group out of the grid, so it does not collide with itself
+		colors := make([]TileColor, len(group))
+		for i, cell := range group {
+			colors[i] = getBlock(grid[cell.Row], cell.Col)
+			grid[cell.Row] = setBlock(grid[cell.Row], cell.Col, Transparent)
+		}
+		// Fall until a block would land on the floor or another block
+		drop := uint8(0)
+		for canDrop(grid, group, drop+1) {
+			drop++
+		}
+		for i, cell := range group {
+			grid[cell.Row+drop] = setBlock(grid[cell.Row+drop], cell.Col, colors[i])
+		}
+		moved = moved || (drop > 0)
+	}
+	return moved
+}
+
+/*
+ Checks if a group of blocks fits in the grid after falling some rows.
+
+ @param grid  Grid, without the group in it.
+ @param group Cells of the group.
+ @param drop  Number of rows the group falls.
+
+ @return True if every block of the group lands on an empty cell on the board.
+*/
+func canDrop(grid *BoardGrid, group []Cell, drop uint8) bool {
+	for _, cell := range group {
+		row := cell.Row + drop
+		if (row >= BoardHeight) || (getBlock(grid[row], cell.Col) != Transparent) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,83 @@
+/*
+ * File:        gravity_test.go
+ *
+ * Author:      Schuyler Martin <schuylermartin45@gmail.com>
+ *
+ * Description: Tests for how blocks fall after rows are cleared.
+ */
+package model
+
+import (
+	"testing"
+)
+
+/***** Tests *****/
+
+/*
+ After the same clear, naive gravity leaves a block floating over a gap, where
+ sticky gravity drops it into the gap.
+*/
+func TestClearGravityDiffers(t *testing.T) {
+	cases := map[ClearGravity][]string{
+		NaiveShift: {
+			".........I",
+			"....T....I",
+			"III..IIIII",
+		},
+		Sticky: {
+			".........I",
+			".........I",
+			"III.TIIIII",
+		},
+	}
+	for gravity, expected := range cases {
+		b := newTestBoard(t,
+			"....T.....",
+			"IIIIIIIII.",
+			"III..IIII.",
+		)
+		b.SetClearGravity(gravity)
+		dropTile(t, b, Red, 9)
+		if b.GetLines() != 1 {
+			t.Errorf("gravity %d cleared %d lines, expected 1", gravity, b.GetLines())
+		}
+		checkBottomRows(t, b, expected...)
+	}
+}
+
+/*
+ A block that falls into a gap after a clear fills its row, which is cleared as
+ the second link of a chain.
+*/
+func TestStickyChain(t *testing.T) {
+	b := newTestBoard(t,
+		"I.........",
+		"....T.....",
+		"IIIIIIIIII",
+		"IIII.IIIII",
+	)
+	chain := clearRowsSticky(&b.grid)
+	if (len(chain) != 2) || (chain[0] != 1) || (chain[1] != 1) {
+		t.Fatalf("cleared %v rows per link, expected [1 1]", chain)
+	}
+	checkBottomRows(t, b,
+		"..........",
+		"I.........",
+	)
+
+	// Locking a tile into the same board reports the chain
+	b = newTestBoard(t,
+		"I.........",
+		"....T.....",
+		"IIIIIIIII.",
+		"IIII.IIII.",
+	)
+	b.SetClearGravity(Sticky)
+	dropTile(t, b, Red, 9)
+	if b.LastChainLength() != 2 {
+		t.Errorf("chain was %d links long, expected 2", b.LastChainLength())
+	}
+	if b.GetLines() != 2 {
+		t.Errorf("cleared %d lines, expected 2", b.GetLines())
+	}
+}
@@ -0,0 +1,68 @@
+/*
+ * File:        history.go
+ *
+ * Author:      Schuyler Martin <schuylermartin45@gmail.com>
+ *
+ * Description: Log of actions performed on the board, for post-game analysis
+ *              and debugging reported issues.
+ */
+package model
+
+/***** Types *****/
+
+// HistoryEntry records a single action performed on the board.
+type HistoryEntry struct {
+	// Number of iterations the game had gone through when the action happened
+	Tick uint32
+	// Action that was performed
+	Action Action
+	// Flag indicates if the action changed the board
+	Applied bool
+	// Base score after the action was performed
+	Score uint32
+}
+
+/***** Methods *****/
+
+/*
+ Sets whether actions performed on the board are recorded. History is off by
+ default. Turning history off keeps the entries recorded so far.
+
+ Recording is not bounded, every action performed is kept for the rest of the
+ game. An action is only a few bytes, so this stays small for any game a person
+ can play.
+
+ @param enabled True to record actions. False to stop recording.
+*/
+func (b *Board) EnableHistory(enabled bool) {
+	b.historyEnabled = enabled
+}
+
+/*
+ Get the actions recorded so far, oldest first.
+
+ @return The recorded actions.
+*/
+func (b Board) History() []HistoryEntry {
+	return b.history
+}
+
+/***** Internal Methods *****/
+
+/*
+ Records an action, if history is enabled.
+
+ @param action  Action that was performed.
+ @param applied Flag indicates if the action changed the board.
+*/
+func (b *Board) recordAction(action Action, applied bool) {
+	if !b.historyEnabled {
+		return
+	}
+	b.history = append(b.history, HistoryEntry{
+		Tick:    b.iteration,
+		Action:  action,
+		Applied: applied,
+		Score:   b.score,
+	})
+}
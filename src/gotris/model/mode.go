@@ -0,0 +1,150 @@
+/*
+ * File:        mode.go
+ *
+ * Author:      Schuyler Martin <schuylermartin45@gmail.com>
+ *
+ * Description: Game modes that change the rules of Gotris.
+ */
+package model
+
+import (
+	"time"
+)
+
+/***** Constants *****/
+
+// Number of rows zen mode clears to make room. A tile will always fit in the
+// space that is cleared.
+const zenClearRows = TileSize
+
+// Survival mode garbage timing. A row of garbage rises every interval. The
+// interval shrinks by one step every level, until it reaches the floor.
+const (
+	survivalInterval      time.Duration = 10 * time.Second
+	survivalIntervalStep  time.Duration = 500 * time.Millisecond
+	survivalIntervalFloor time.Duration = 2 * time.Second
+)
+
+/***** Types *****/
+
+// GameMode describes a set of rules the board plays by.
+type GameMode interface {
+	/*
+	 Handles the board reaching the end of the game, which happens when the
+	 stack reaches the top of the board.
+
+	 @param b Board that reached the end of the game.
+
+	 @return True if the game should end. False if the game continues.
+	*/
+	OnGameOver(b *Board) bool
+
+	/*
+	 Handles time passing on the board, for rules that play out over time.
+
+	 @param b  Board time passed on.
+	 @param dt Time that passed.
+
+	 @return True if the game should end. False if the game continues.
+	*/
+	OnTick(b *Board, dt time.Duration) bool
+}
+
+// ClassicMode plays by the usual rules. The game ends when the stack reaches the
+// top of the board.
+type ClassicMode struct{}
+
+// ZenMode is an endless game. When the stack reaches the top of the board, the
+// bottom of the stack is cleared away to make room.
+type ZenMode struct{}
+
+// SurvivalMode raises a row of garbage from the bottom of the board every so
+// often, faster as the level increases. The game ends when the garbage pushes
+// the stack off of the top of the board.
+type SurvivalMode struct {
+	// Time left until the next row of garbage rises
+	countdown time.Duration
+}
+
+/***** Functions *****/
+
+/*
+ Constructs a survival game mode. Every game needs its own, as it keeps track of
+ when garbage rises.
+
+ @return A new survival mode.
+*/
+func NewSurvivalMode() *SurvivalMode {
+	return &SurvivalMode{countdown: survivalInterval}
+}
+
+/***** Internal Functions *****/
+
+/*
+ Calculates how often garbage rises in survival mode.
+
+ @param level Current level of the game.
+
+ @return Time between rows of garbage.
+*/
+func calcSurvivalInterval(level uint8) time.Duration {
+	interval := survivalInterval - (time.Duration(level) * survivalIntervalStep)
+	if interval < survivalIntervalFloor {
+		return survivalIntervalFloor
+	}
+	return interval
+}
+
+/***** Methods *****/
+
+// OnGameOver ends the game.
+func (m ClassicMode) OnGameOver(b *Board) bool {
+	return true
+}
+
+// OnTick does nothing, the classic rules do not change over time.
+func (m ClassicMode) OnTick(b *Board, dt time.Duration) bool {
+	return false
+}
+
+// OnGameOver clears the bottom of the stack to keep the game going.
+func (m ZenMode) OnGameOver(b *Board) bool {
+	b.ShiftDown(zenClearRows)
+	return false
+}
+
+// OnTick does nothing, zen rules do not change over time.
+func (m ZenMode) OnTick(b *Board, dt time.Duration) bool {
+	return false
+}
+
+// OnGameOver ends the game.
+func (m *SurvivalMode) OnGameOver(b *Board) bool {
+	return true
+}
+
+// OnTick raises garbage once it is due. Garbage waits until the dropping tile
+// has locked and filled rows are cleared, as neither move with the stack.
+func (m *SurvivalMode) OnTick(b *Board, dt time.Duration) bool {
+	if m.countdown > dt {
+		m.countdown -= dt
+		return false
+	}
+	m.countdown = 0
+	if (b.tile != nil) || b.IsClearing() {
+		return false
+	}
+	m.countdown = calcSurvivalInterval(b.GetLevel())
+	return !b.AddGarbageLines(1)
+}
+
+/*
+ Get the time left until the next row of garbage rises, so views can warn the
+ player.
+
+ @return Time until the next row of garbage. 0 if garbage is waiting on the
+         dropping tile to lock or rows to clear.
+*/
+func (m SurvivalMode) GarbageCountdown() time.Duration {
+	return m.countdown
+}
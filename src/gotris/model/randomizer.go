@@ -0,0 +1,277 @@
+/*
+ * File:        randomizer.go
+ *
+ * Author:      Schuyler Martin <schuylermartin45@gmail.com>
+ *
+ * Description: Sources of the tiles that drop onto the board.
+ */
+package model
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+)
+
+/***** Types *****/
+
+// Randomizer picks the sequence of tiles that drop onto the board. Tests can
+// provide their own to script the tiles that come next.
+type Randomizer interface {
+	// Picks the next tile, in its starting orientation.
+	NextTile() Tile
+}
+
+// UniformRandomizer picks every tile independently, with every shape equally
+// likely. Long droughts of a shape are possible.
+type UniformRandomizer struct {
+	random *rand.Rand
+}
+
+// BagRandomizer deals tiles from a shuffled bag holding one of every shape. The
+// bag is refilled once it is empty, so no shape is ever far away.
+type BagRandomizer struct {
+	random *rand.Rand
+	// Source of the random number generator, if it can be copied
+	source *replaySource
+	// Tiles left in the bag, dealt from the end
+	bag []Tile
+}
+
+// SequenceRandomizer deals a fixed sequence of tiles, in order, for tests and
+// puzzles.
+type SequenceRandomizer struct {
+	sequence []Tile
+	// Index of the next tile in the sequence
+	next int
+	// Starts the sequence over once every tile has been dealt
+	loop bool
+	// Set once a sequence that does not loop runs out of tiles
+	err error
+}
+
+// forkableRandomizer is implemented by randomizers that can be copied, so a
+// cloned board picks the same tiles as the original without changing the tiles
+// the original picks.
+type forkableRandomizer interface {
+	// Copies the randomizer, in its current state.
+	fork() Randomizer
+}
+
+// replaySource is a random number source that can be copied. The sources in
+// `math/rand` can't be, so a copy replays the numbers drawn so far from a new
+// source with the same seed. The replay waits until the copy draws a number, so
+// copies that are never drawn from cost next to nothing.
+type replaySource struct {
+	seed int64
+	// Numbers drawn so far
+	draws uint64
+	// Source the numbers are drawn from. Nil until the first number is drawn.
+	source rand.Source64
+}
+
+/***** Variables *****/
+
+// ErrSequenceExhausted is reported once a sequence that does not loop has dealt
+// every tile.
+var ErrSequenceExhausted = errors.New("tile sequence exhausted")
+
+/***** Functions *****/
+
+/*
+ Constructs a randomizer that picks every tile independently.
+
+ @param random Random number generator to pick tiles with.
+
+ @return A new uniform randomizer.
+*/
+func NewUniformRandomizer(random *rand.Rand) *UniformRandomizer {
+	return &UniformRandomizer{random: random}
+}
+
+/*
+ Constructs a randomizer that deals tiles from a shuffled bag.
+
+ @param random Random number generator to shuffle the bag with.
+
+ @return A new bag randomizer, starting with a full bag.
+*/
+func NewBagRandomizer(random *rand.Rand) *BagRandomizer {
+	return &BagRandomizer{random: random}
+}
+
+/*
+ Constructs a randomizer that deals a fixed sequence of tiles.
+
+ @param colors Colors identifying the tiles to deal, in order.
+ @param loop   True to start over once every tile has been dealt. False to keep
+               dealing the last tile, reporting `ErrSequenceExhausted`.
+
+ @return A new sequence randomizer AND an error if the sequence is empty or a
+         color does not identify a tile.
+*/
+func NewSequenceRandomizer(colors []TileColor, loop bool) (*SequenceRandomizer, error) {
+	if len(colors) == 0 {
+		return nil, errors.New("tile sequence is empty")
+	}
+	r := &SequenceRandomizer{loop: loop}
+	for _, color := range colors {
+		tile, ok := lookupTile(color)
+		if !ok {
+			return nil, fmt.Errorf("no tile has the color %d", color)
+		}
+		r.sequence = append(r.sequence, tile)
+	}
+	return r, nil
+}
+
+/*
+ Constructs a random number source that can be copied.
+
+ @param seed Seed for the source.
+
+ @return A new source.
+*/
+func newReplaySource(seed int64) *replaySource {
+	return &replaySource{seed: seed}
+}
+
+/***** Methods *****/
+
+/*
+ Picks the next tile at random.
+
+ @return The next tile.
+*/
+func (u *UniformRandomizer) NextTile() Tile {
+	return *PickTile(u.random)
+}
+
+/*
+ Deals the next tile from the bag, refilling the bag if it is empty.
+
+ @return The next tile.
+*/
+func (r *BagRandomizer) NextTile() Tile {
+	if len(r.bag) == 0 {
+		r.bag = append(r.bag, tiles[:]...)
+		r.random.Shuffle(len(r.bag), func(i int, j int) {
+			r.bag[i], r.bag[j] = r.bag[j], r.bag[i]
+		})
+	}
+	tile := r.bag[len(r.bag)-1]
+	r.bag = r.bag[:len(r.bag)-1]
+	return tile
+}
+
+/*
+ Deals the next tile in the sequence. Once a sequence that does not loop runs
+ out, the last tile is dealt again so the game can continue, and `Err()`
+ reports the sequence as exhausted.
+
+ @return The next tile.
+*/
+func (r *SequenceRandomizer) NextTile() Tile {
+	if r.next >= len(r.sequence) {
+		if !r.loop {
+			r.err = ErrSequenceExhausted
+			return r.sequence[len(r.sequence)-1]
+		}
+		r.next = 0
+	}
+	tile := r.sequence[r.next]
+	r.next++
+	return tile
+}
+
+/*
+ Reports whether the sequence ran out of tiles.
+
+ @return `ErrSequenceExhausted` once a sequence that does not loop has been
+         asked for more tiles than it holds. Nil otherwise.
+*/
+func (r SequenceRandomizer) Err() error {
+	return r.err
+}
+
+/***** Internal Methods *****/
+
+/*
+ Copies the bag, in its current state. The copy draws from a copy of the random
+ number generator, unless it can't be copied, in which case it is shared.
+
+ @return A copy of the randomizer.
+*/
+func (r *BagRandomizer) fork() Randomizer {
+	forked := &BagRandomizer{random: r.random, bag: append([]Tile(nil), r.bag...)}
+	if r.source != nil {
+		forked.source = r.source.fork()
+		forked.random = rand.New(forked.source)
+	}
+	return forked
+}
+
+/*
+ Copies the sequence, in its current state.
+
+ @return A copy of the randomizer.
+*/
+func (r *SequenceRandomizer) fork() Randomizer {
+	forked := *r
+	return &forked
+}
+
+/*
+ Draws a random 63-bit number.
+
+ @return A non-negative random number.
+*/
+func (s *replaySource) Int63() int64 {
+	return s.draw().Int63()
+}
+
+/*
+ Draws a random 64-bit number.
+
+ @return A random number.
+*/
+func (s *replaySource) Uint64() uint64 {
+	return s.draw().Uint64()
+}
+
+/*
+ Re-seeds the source, forgetting every number drawn so far.
+
+ @param seed New seed for the source.
+*/
+func (s *replaySource) Seed(seed int64) {
+	s.seed = seed
+	s.draws = 0
+	s.source = nil
+}
+
+/*
+ Copies the source. The copy draws the same numbers the original draws next.
+
+ @return A copy of the source.
+*/
+func (s *replaySource) fork() *replaySource {
+	return &replaySource{seed: s.seed, draws: s.draws}
+}
+
+/*
+ Gets the source to draw the next number from, replaying the numbers drawn so
+ far the first time a copy is drawn from.
+
+ @return The source, ready to draw the next number.
+*/
+func (s *replaySource) draw() rand.Source64 {
+	if s.source == nil {
+		s.source = rand.NewSource(s.seed).(rand.Source64)
+		for i := uint64(0); i < s.draws; i++ {
+			s.source.Int63()
+		}
+	}
+	s.draws++
+	return s.source
+}
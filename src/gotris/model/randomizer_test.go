@@ -0,0 +1,235 @@
+/*
+ * File:        randomizer_test.go
+ *
+ * Author:      Schuyler Martin <schuylermartin45@gmail.com>
+ *
+ * Description: Tests for picking the tiles that drop onto the board.
+ */
+package model
+
+import (
+	"testing"
+)
+
+/***** Tests *****/
+
+/*
+ The tiles peeked at are the tiles that spawn.
+*/
+func TestPeekMatchesSpawns(t *testing.T) {
+	b := NewBoardWithSeed(1)
+	peeked := b.PeekNext(10)
+	spawned := spawnColors(b, len(peeked))
+	for i := range peeked {
+		if spawned[i] != peeked[i] {
+			t.Fatalf("spawned %v, peeked %v", spawned, peeked)
+		}
+	}
+}
+
+/*
+ Peeking on a clone changes neither the tiles the original spawns, nor its
+ checksum.
+*/
+func TestPeekOnCloneKeepsOriginal(t *testing.T) {
+	b := NewBoardWithSeed(1)
+	reference := NewBoardWithSeed(1)
+	checksum := b.Checksum()
+	clone := b.Clone()
+	cloned := clone.PeekNext(20)
+	if b.Checksum() != checksum {
+		t.Error("peeking on the clone changed the original's checksum")
+	}
+	if !b.Equal(reference) {
+		t.Error("peeking on the clone changed the original")
+	}
+	expected := spawnColors(reference, 20)
+	spawned := spawnColors(b, 20)
+	for i := range expected {
+		if (spawned[i] != expected[i]) || (cloned[i] != expected[i]) {
+			t.Fatalf("spawned %v and peeked %v on the clone, expected %v", spawned, cloned, expected)
+		}
+	}
+}
+
+/*
+ Peeking does not change the checksum.
+*/
+func TestPeekKeepsChecksum(t *testing.T) {
+	b := NewBoardWithSeed(1)
+	b.Next()
+	checksum := b.Checksum()
+	b.PeekNext(5)
+	if b.Checksum() != checksum {
+		t.Error("peeking changed the checksum")
+	}
+}
+
+/*
+ Peeking looks ahead in a copy of the randomizer, so the board's randomizer is
+ not asked for any tiles.
+*/
+func TestPeekKeepsRandomizer(t *testing.T) {
+	randomizer, err := NewSequenceRandomizer([]TileColor{Red, Cyan, Grey}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := NewBoardWithRandomizer(randomizer)
+	b.Next()
+	peeked := b.PeekNext(4)
+	expected := []TileColor{Cyan, Grey, Grey, Grey}
+	for i := range expected {
+		if peeked[i] != expected[i] {
+			t.Fatalf("peeked %v, expected %v", peeked, expected)
+		}
+	}
+	if randomizer.next != 2 {
+		t.Errorf("peeking dealt %d tiles from the randomizer", randomizer.next-2)
+	}
+	if randomizer.Err() != nil {
+		t.Errorf("peeking exhausted the sequence: %v", randomizer.Err())
+	}
+}
+
+/*
+ Boards with the same seed spawn tiles in the same random rotations.
+*/
+func TestRandomSpawnRotationSeeded(t *testing.T) {
+	const spawns = 30
+	var played [2][]Tile
+	rotations := make(map[uint8]bool)
+	for i := range played {
+		b := NewBoardWithSeed(7)
+		b.SetRandomSpawnRotation(true)
+		for len(played[i]) < spawns {
+			b.Next()
+			tile, _, _ := b.GetActiveTile()
+			played[i] = append(played[i], tile)
+			rotations[b.rotationState] = true
+			b.Clear()
+		}
+	}
+	for i := range played[0] {
+		if !played[0][i].Equals(played[1][i]) {
+			t.Fatalf("spawn %d was rotated differently with the same seed", i)
+		}
+	}
+	if len(rotations) < 2 {
+		t.Errorf("%d spawns were all in the same rotation", spawns)
+	}
+}
+
+/*
+ A sequence deals its tiles in order, starting over if it loops and repeating
+ its last tile if it doesn't.
+*/
+func TestSequenceRandomizer(t *testing.T) {
+	sequence := []TileColor{Red, Cyan, Grey}
+	cases := map[bool][]TileColor{
+		true:  {Red, Cyan, Grey, Red, Cyan, Grey, Red},
+		false: {Red, Cyan, Grey, Grey, Grey, Grey, Grey},
+	}
+	for loop, expected := range cases {
+		randomizer, err := NewSequenceRandomizer(sequence, loop)
+		if err != nil {
+			t.Fatal(err)
+		}
+		spawned := spawnColors(NewBoardWithRandomizer(randomizer), len(expected))
+		for i := range expected {
+			if spawned[i] != expected[i] {
+				t.Fatalf("loop %v: spawned %v, expected %v", loop, spawned, expected)
+			}
+		}
+		if exhausted := (randomizer.Err() == ErrSequenceExhausted); exhausted == loop {
+			t.Errorf("loop %v: sequence reported %v", loop, randomizer.Err())
+		}
+	}
+	if _, err := NewSequenceRandomizer(nil, true); err == nil {
+		t.Error("empty sequence was accepted")
+	}
+	if _, err := NewSequenceRandomizer([]TileColor{Red, Transparent}, true); err == nil {
+		t.Error("sequence with a color that is not a tile was accepted")
+	}
+}
+
+/*
+ The queue state agrees with the individual getters, and its next tile is the
+ tile that spawns next.
+*/
+func TestQueueState(t *testing.T) {
+	b := NewBoardWithSeed(3)
+	if state := b.QueueState(); state.HasActive || (len(state.Next) != 0) {
+		t.Fatalf("new board has queue state %+v", state)
+	}
+	for i := 0; i < 10; i++ {
+		b.Next()
+		state := b.QueueState()
+		active, depth, ok := b.GetActiveTile()
+		if !state.HasActive || !ok || !state.Active.Equals(active) || (state.Depth != depth) {
+			t.Fatalf("queue state has tile %+v at depth %d, expected %+v at depth %d", state.Active, state.Depth, active, depth)
+		}
+		if (len(state.Next) != 1) || !state.Next[0].Equals(b.GetNextTile()) {
+			t.Fatalf("queue state has next tiles %+v, expected %+v", state.Next, b.GetNextTile())
+		}
+		if peeked := b.PeekNext(1); state.Next[0].GetColor() != peeked[0] {
+			t.Fatalf("queue state has next tile %d, peeked %d", state.Next[0].GetColor(), peeked[0])
+		}
+		b.Clear()
+		b.Next()
+		if color, _ := b.ActiveTileColor(); color != state.Next[0].GetColor() {
+			t.Fatalf("spawned tile %d, expected %d", color, state.Next[0].GetColor())
+		}
+		b.Clear()
+	}
+}
+
+/*
+ Boards seeded with the same code spawn the same tiles, and a different code
+ spawns different tiles.
+*/
+func TestSeedFromString(t *testing.T) {
+	const spawns = 30
+	if SeedFromString("2024-06-01") != SeedFromString("2024-06-01") {
+		t.Fatal("the same code gave different seeds")
+	}
+	var played [3][]TileColor
+	for i, code := range []string{"2024-06-01", "2024-06-01", "2024-06-02"} {
+		b := NewBoard()
+		b.SetSeedFromString(code)
+		played[i] = spawnColors(b, spawns)
+	}
+	same := true
+	different := false
+	for i := 0; i < spawns; i++ {
+		same = same && (played[0][i] == played[1][i])
+		different = different || (played[0][i] != played[2][i])
+	}
+	if !same {
+		t.Errorf("the same code spawned %v and %v", played[0], played[1])
+	}
+	if !different {
+		t.Errorf("different codes both spawned %v", played[0])
+	}
+}
+
+/***** Internal Functions *****/
+
+/*
+ Spawns tiles, recording the tiles that spawn. Every tile is cleared off of the
+ board once it spawns, so the board never fills up.
+
+ @param b Board to play on.
+ @param n Number of tiles to spawn.
+
+ @return The colors identifying the tiles that spawned, in order.
+*/
+func spawnColors(b *Board, n int) []TileColor {
+	var colors []TileColor
+	for len(colors) < n {
+		b.Next()
+		color, _ := b.ActiveTileColor()
+		colors = append(colors, color)
+		b.Clear()
+	}
+	return colors
+}
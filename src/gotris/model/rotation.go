@@ -0,0 +1,126 @@
+/*
+ * File:        rotation.go
+ *
+ * Author:      Schuyler Martin <schuylermartin45@gmail.com>
+ *
+ * Description: Rules for how tiles rotate when they are up against walls and
+ *              the stack.
+ */
+package model
+
+/***** Types *****/
+
+// RotationSystem describes how a tile rotates when its rotated shape does not
+// fit where it is.
+type RotationSystem uint8
+
+// RotationSystem enumerations
+const (
+	// Rotations that don't fit fail, like older games
+	ClassicRotation RotationSystem = 0
+	// Rotations that don't fit try the wall kicks of the Super Rotation System
+	// used by modern games, nudging the tile into a spot where it fits
+	SRS RotationSystem = 1
+)
+
+// kick is an offset to try a rotated tile at. Positive x moves the tile right,
+// positive y moves the tile up, as in the SRS tables.
+type kick struct {
+	x int8
+	y int8
+}
+
+/***** Variables *****/
+
+// SRS kicks tried when rotating clockwise from each rotation state (0 is the
+// spawn orientation), in order. Every tile but the pipe uses these.
+var srsKicks = [4][5]kick{
+	{{0, 0}, {-1, 0}, {-1, 1}, {0, -2}, {-1, -2}},
+	{{0, 0}, {1, 0}, {1, -1}, {0, 2}, {1, 2}},
+	{{0, 0}, {1, 0}, {1, 1}, {0, -2}, {1, -2}},
+	{{0, 0}, {-1, 0}, {-1, -1}, {0, 2}, {-1, 2}},
+}
+
+// SRS kicks tried when rotating the pipe clockwise from each rotation state.
+var srsKicksPipe = [4][5]kick{
+	{{0, 0}, {-2, 0}, {1, 0}, {-2, -1}, {1, 2}},
+	{{0, 0}, {-1, 0}, {2, 0}, {-1, 2}, {2, -1}},
+	{{0, 0}, {2, 0}, {-1, 0}, {2, 1}, {-1, -2}},
+	{{0, 0}, {1, 0}, {-2, 0}, {1, -2}, {-2, 1}},
+}
+
+/***** Internal Functions *****/
+
+/*
+ Moves a tile across the board.
+
+ @param tile Tile to move.
+ @param dx   Number of columns to move by. Negative values move left.
+
+ @return The moved tile AND true if it moved the whole way. False if a wall is
+         in the way.
+*/
+func shiftTile(tile Tile, dx int8) (Tile, bool) {
+	direction := Right
+	if dx < 0 {
+		direction = Left
+		dx = -dx
+	}
+	for ; dx > 0; dx-- {
+		moved := tile
+		moved.MoveX(direction)
+		if moved.Equals(tile) {
+			return tile, false
+		}
+		tile = moved
+	}
+	return tile, true
+}
+
+/***** Methods *****/
+
+/*
+ Sets how tiles rotate when their rotated shape does not fit where they are.
+
+ @param system Rotation system to use. `ClassicRotation` is the default.
+*/
+func (b *Board) SetRotationSystem(system RotationSystem) {
+	b.rotationSystem = system
+}
+
+/*
+ Get how tiles rotate when their rotated shape does not fit where they are.
+
+ @return The rotation system in use.
+*/
+func (b Board) GetRotationSystem() RotationSystem {
+	return b.rotationSystem
+}
+
+/***** Internal Methods *****/
+
+/*
+ Tries a rotated tile at each of the SRS kicks for the current rotation state,
+ in order.
+
+ @param rotated Current tile, already rotated in place.
+
+ @return The tile and depth of the first kick that fits AND true if one fit.
+*/
+func (b Board) kickSRS(rotated Tile) (Tile, uint8, bool) {
+	kicks := srsKicks[b.rotationState]
+	if rotated.color == Red {
+		kicks = srsKicksPipe[b.rotationState]
+	}
+	for _, k := range kicks {
+		depth := int(b.tileDepth) - int(k.y)
+		if depth < 0 {
+			continue
+		}
+		kicked, ok := shiftTile(rotated, k.x)
+		if ok && TileFits(b.grid, kicked, uint8(depth)) {
+			return kicked, uint8(depth), true
+		}
+	}
+	return rotated, b.tileDepth, false
+}
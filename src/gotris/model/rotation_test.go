@@ -0,0 +1,90 @@
+/*
+ * File:        rotation_test.go
+ *
+ * Author:      Schuyler Martin <schuylermartin45@gmail.com>
+ *
+ * Description: Tests for how tiles rotate against walls and the stack.
+ */
+package model
+
+import (
+	"testing"
+)
+
+/***** Tests *****/
+
+/*
+ A pipe against the left wall, with a block where it would rotate to, stays put
+ with classic rotation and is kicked up and over with SRS.
+*/
+func TestRotationSystemWallKick(t *testing.T) {
+	cases := map[RotationSystem][]Cell{
+		ClassicRotation: nil,
+		SRS:             {{10, 2}, {10, 3}, {10, 4}, {10, 5}},
+	}
+	for system, expected := range cases {
+		b := newTestBoard(t,
+			"..I.......",
+			"..........",
+			"..........",
+			"..........",
+			"..........",
+			"..........",
+			"..........",
+			"..........",
+		)
+		b.SetRotationSystem(system)
+		spawnTile(t, b, Red)
+		b.MoveLeftN(BoardWidth)
+		for i := 0; i < 12; i++ {
+			b.Apply(ActionDown)
+		}
+		before := b.tileCells()
+		rotated := b.Rotate()
+		if rotated != (expected != nil) {
+			t.Fatalf("system %d: rotated %v, expected %v", system, rotated, expected != nil)
+		}
+		if expected == nil {
+			expected = before
+		}
+		checkCells(t, b.tileCells(), expected)
+	}
+}
+
+/*
+ A pipe against the right wall turns the same way with either system, as the
+ turned tile fits without a kick.
+*/
+func TestRotationSystemRightWall(t *testing.T) {
+	for _, system := range []RotationSystem{ClassicRotation, SRS} {
+		b := newTestBoard(t)
+		b.SetRotationSystem(system)
+		spawnTile(t, b, Red)
+		b.MoveRightN(BoardWidth)
+		if !b.Rotate() {
+			t.Fatalf("system %d: pipe did not rotate off of the right wall", system)
+		}
+		checkCells(t, b.tileCells(), []Cell{{3, 5}, {3, 6}, {3, 7}, {3, 8}})
+	}
+}
+
+/***** Internal Functions *****/
+
+/*
+ Checks the cells a tile covers.
+
+ @param t        Test doing the check.
+ @param actual   Cells the tile covers.
+ @param expected Cells the tile should cover, in the same order.
+*/
+func checkCells(t *testing.T, actual []Cell, expected []Cell) {
+	t.Helper()
+	if len(actual) != len(expected) {
+		t.Fatalf("tile covers %v, expected %v", actual, expected)
+	}
+	for i := range expected {
+		if actual[i] != expected[i] {
+			t.Fatalf("tile covers %v, expected %v", actual, expected)
+		}
+	}
+}
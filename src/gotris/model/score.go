@@ -0,0 +1,147 @@
+/*
+ * File:        score.go
+ *
+ * Author:      Schuyler Martin <schuylermartin45@gmail.com>
+ *
+ * Description: Rules for how many points a tile scores when it locks.
+ */
+package model
+
+/***** Types *****/
+
+// SpinType describes a tile that was spun into place as it locked.
+type SpinType uint8
+
+// SpinType enumerations
+const (
+	// The tile was not spun into place
+	SpinNone SpinType = 0
+	// The tri-point tile was rotated into a spot with at least 3 of the 4 cells
+	// diagonal to its center filled
+	SpinT SpinType = 1
+)
+
+/*
+ Scorer calculates the points a tile scores when it locks. Scores are in the
+ board's base score, which is displayed x100. With sticky gravity, the scorer is
+ called once per link of a chain, and the board multiplies the points by how
+ deep into the chain the link is (counting from 1).
+
+ @param linesCleared Number of rows cleared. 0 if no rows were cleared.
+ @param spin         How the tile was spun into place.
+ @param combo        Number of locks in a row that cleared rows, before this one.
+                     With sticky gravity, every link of a chain counts as a lock.
+ @param level        Level of the game when the tile locked.
+ @param backToBack   True if this clear and the previous one were both difficult
+                     (four rows or a spin).
+
+ @return The points scored.
+*/
+type Scorer func(linesCleared uint8, spin SpinType, combo int, level uint8, backToBack bool) uint16
+
+/***** Variables *****/
+
+// Base points of a guideline clear, indexed by the number of rows cleared at once
+var guidelineLinePoints = [TileSize + 1]uint16{0, 1, 3, 5, 8}
+
+// Base points of a guideline spin, indexed by the number of rows cleared at once
+var guidelineSpinPoints = [TileSize + 1]uint16{4, 8, 12, 16, 16}
+
+/***** Functions *****/
+
+/*
+ Scores locks by the modern Tetris guideline, scaled down to the base score:
+ clears score 1, 3, 5, and 8 for one to four rows, and spins score 4 plus 4 per
+ row. Difficult clears back-to-back score half again as much. Combos score half
+ a point per lock in the combo. Everything is multiplied by the level, counting
+ from 1. Boards score with this by default.
+
+ @param linesCleared Number of rows cleared. 0 if no rows were cleared.
+ @param spin         How the tile was spun into place.
+ @param combo        Number of locks in a row that cleared rows, before this one.
+ @param level        Level of the game when the tile locked.
+ @param backToBack   True if this clear and the previous one were both difficult.
+
+ @return The points scored.
+*/
+func GuidelineScorer(linesCleared uint8, spin SpinType, combo int, level uint8, backToBack bool) uint16 {
+	if linesCleared > TileSize {
+		linesCleared = TileSize
+	}
+	points := guidelineLinePoints[linesCleared]
+	if spin != SpinNone {
+		points = guidelineSpinPoints[linesCleared]
+	}
+	if backToBack {
+		points += points / 2
+	}
+	if (linesCleared > 0) && (combo > 0) {
+		points += uint16(combo) / 2
+	}
+	return points * (uint16(level) + 1)
+}
+
+/***** Methods *****/
+
+/*
+ Sets how locked tiles are scored, for variants that score differently.
+
+ @param scorer Calculates the points each lock scores. Nil restores the default,
+               `GuidelineScorer`.
+*/
+func (b *Board) SetScorer(scorer Scorer) {
+	if scorer == nil {
+		scorer = GuidelineScorer
+	}
+	b.scorer = scorer
+}
+
+/***** Internal Methods *****/
+
+/*
+ Detects if the current tile was spun into place. Only the tri-point tile can
+ spin, and only if its last move was a rotation.
+
+ @return How the tile was spun into place.
+*/
+func (b Board) detectSpin() SpinType {
+	if (b.tile == nil) || (b.tile.color != Grey) || !b.lastMoveRotate {
+		return SpinNone
+	}
+	cells := b.tileCells()
+	if len(cells) != int(TileSize) {
+		return SpinNone
+	}
+	// The center block is the one the other three blocks are next to
+	tileCell := make(map[Cell]bool)
+	for _, cell := range cells {
+		tileCell[cell] = true
+	}
+	for _, center := range cells {
+		neighbors := 0
+		for _, offset := range [4][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+			row, col := int(center.Row)+offset[0], int(center.Col)+offset[1]
+			if (row >= 0) && (col >= 0) && tileCell[Cell{Row: uint8(row), Col: uint8(col)}] {
+				neighbors++
+			}
+		}
+		if neighbors != 3 {
+			continue
+		}
+		// Walls and the floor count as filled corners
+		corners := 0
+		for _, offset := range [4][2]int{{-1, -1}, {-1, 1}, {1, -1}, {1, 1}} {
+			row, col := int(center.Row)+offset[0], int(center.Col)+offset[1]
+			if (col < 0) || (col >= int(BoardWidth)) || (row >= int(BoardHeight)) {
+				corners++
+			} else if (row >= 0) && (getBlock(b.grid[row], uint8(col)) != Transparent) {
+				corners++
+			}
+		}
+		if corners >= 3 {
+			return SpinT
+		}
+		return SpinNone
+	}
+	return SpinNone
+}
@@ -0,0 +1,265 @@
+/*
+ * File:        score_test.go
+ *
+ * Author:      Schuyler Martin <schuylermartin45@gmail.com>
+ *
+ * Description: Tests for scoring locked tiles.
+ */
+package model
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+/***** Tests *****/
+
+/*
+ The guideline scorer follows the scoring table.
+*/
+func TestGuidelineScorer(t *testing.T) {
+	cases := []struct {
+		name       string
+		lines      uint8
+		spin       SpinType
+		combo      int
+		level      uint8
+		backToBack bool
+		expected   uint16
+	}{
+		{"no clear", 0, SpinNone, 0, 0, false, 0},
+		{"single", 1, SpinNone, 0, 0, false, 1},
+		{"double", 2, SpinNone, 0, 0, false, 3},
+		{"triple", 3, SpinNone, 0, 0, false, 5},
+		{"tetris", 4, SpinNone, 0, 0, false, 8},
+		{"tetris at level 2", 4, SpinNone, 0, 2, false, 24},
+		{"back-to-back tetris", 4, SpinNone, 0, 0, true, 12},
+		{"spin without a clear", 0, SpinT, 0, 0, false, 4},
+		{"spin single", 1, SpinT, 0, 0, false, 8},
+		{"combo of 2", 1, SpinNone, 2, 0, false, 2},
+		{"combo without a clear", 0, SpinNone, 2, 0, false, 0},
+	}
+	for _, c := range cases {
+		if points := GuidelineScorer(c.lines, c.spin, c.combo, c.level, c.backToBack); points != c.expected {
+			t.Errorf("%s scored %d, expected %d", c.name, points, c.expected)
+		}
+	}
+}
+
+/*
+ The score delta of a lock matches the scoring table.
+*/
+func TestScoreDeltaMatchesTable(t *testing.T) {
+	for lines := uint8(1); lines <= TileSize; lines++ {
+		// A block is left over, so no clear is perfect
+		rows := []string{"I........."}
+		for i := uint8(0); i < lines; i++ {
+			rows = append(rows, "IIIIIIIII.")
+		}
+		b := newTestBoard(t, rows...)
+		dropTile(t, b, Red, 9)
+		expected := uint32(GuidelineScorer(lines, SpinNone, 0, 0, false))
+		if b.LastScoreDelta() != expected {
+			t.Errorf("clearing %d rows scored %d, expected %d", lines, b.LastScoreDelta(), expected)
+		}
+		if b.GetScore() != expected {
+			t.Errorf("clearing %d rows left a score of %d, expected %d", lines, b.GetScore(), expected)
+		}
+	}
+}
+
+/*
+ A tick keeps the points of a clear, even if it goes on to spawn the next tile,
+ and the following tick starts over from 0.
+*/
+func TestScoreDeltaTick(t *testing.T) {
+	b := newTestBoard(t,
+		"I.........",
+		"IIIIIIIII.",
+		"IIIIIIIII.",
+		"IIIIIIIII.",
+		"IIIIIIIII.",
+	)
+	spawnTile(t, b, Red)
+	b.DropInColumn(9)
+	// Lock, then spawn the next tile
+	b.Tick(2 * b.GetGravityInterval())
+	if _, _, ok := b.GetActiveTile(); !ok {
+		t.Fatal("next tile did not spawn")
+	}
+	if expected := uint32(GuidelineScorer(TileSize, SpinNone, 0, 0, false)); b.LastScoreDelta() != expected {
+		t.Fatalf("tick scored %d, expected %d", b.LastScoreDelta(), expected)
+	}
+	b.Tick(time.Millisecond)
+	if b.LastScoreDelta() != 0 {
+		t.Errorf("score delta of %d carried over to the next tick", b.LastScoreDelta())
+	}
+}
+
+/*
+ A custom scorer is called with the details of every clear, and its points are
+ added to the score. Links deeper into a chain multiply the points.
+*/
+func TestScorerHook(t *testing.T) {
+	type call struct {
+		lines      uint8
+		spin       SpinType
+		combo      int
+		level      uint8
+		backToBack bool
+	}
+	var calls []call
+	b := newTestBoard(t,
+		".....T....",
+		"IIIIIIIII.",
+		"IIIII.IIII",
+	)
+	b.SetClearGravity(Sticky)
+	b.SetScorer(func(lines uint8, spin SpinType, combo int, level uint8, backToBack bool) uint16 {
+		calls = append(calls, call{lines, spin, combo, level, backToBack})
+		return 10 * uint16(lines)
+	})
+	// The pipe fills the middle row, and the block above it falls into the
+	// bottom row
+	dropTile(t, b, Red, 9)
+	expected := []call{
+		{1, SpinNone, 0, 0, false},
+		{1, SpinNone, 1, 0, false},
+	}
+	if len(calls) != len(expected) {
+		t.Fatalf("scorer was called %d times, expected %d", len(calls), len(expected))
+	}
+	for i := range expected {
+		if calls[i] != expected[i] {
+			t.Errorf("call %d was %+v, expected %+v", i, calls[i], expected[i])
+		}
+	}
+	if b.GetScore() != (10 + (2 * 10)) {
+		t.Errorf("score is %d, expected %d", b.GetScore(), 10+(2*10))
+	}
+}
+
+/*
+ A chain that scores more than the largest 16-bit number in one lock reports
+ all of its points in the score delta.
+*/
+func TestScoreDeltaPast16Bits(t *testing.T) {
+	b := newTestBoard(t,
+		".....T....",
+		"IIIIIIIII.",
+		"IIIII.IIII",
+	)
+	b.SetClearGravity(Sticky)
+	b.SetScorer(func(lines uint8, spin SpinType, combo int, level uint8, backToBack bool) uint16 {
+		return 40000
+	})
+	dropTile(t, b, Red, 9)
+	// The second link of the chain scores double
+	if b.LastScoreDelta() != (40000 + (2 * 40000)) {
+		t.Errorf("score delta is %d, expected %d", b.LastScoreDelta(), 40000+(2*40000))
+	}
+	if b.GetScore() != b.LastScoreDelta() {
+		t.Errorf("score is %d, expected the score delta of %d", b.GetScore(), b.LastScoreDelta())
+	}
+}
+
+/*
+ A clear that empties the board is perfect, and scores a bonus on top of the
+ clear. A clear that leaves blocks behind is not.
+*/
+func TestPerfectClear(t *testing.T) {
+	b := newTestBoard(t,
+		"IIIIIIIII.",
+		"IIIIIIIII.",
+		"IIIIIIIII.",
+		"IIIIIIIII.",
+	)
+	events := recordEvents(b)
+	dropTile(t, b, Red, 9)
+	if !b.LastClearWasPerfect() {
+		t.Fatal("emptying the board was not a perfect clear")
+	}
+	if !isGridEmpty(b.grid) {
+		t.Fatalf("board is not empty:\n%s", b.ExportText())
+	}
+	expected := uint32(GuidelineScorer(TileSize, SpinNone, 0, 0, false) + perfectClearBonus[TileSize])
+	if b.GetScore() != expected {
+		t.Errorf("score is %d, expected %d", b.GetScore(), expected)
+	}
+	reported := false
+	for _, event := range *events {
+		reported = reported || (event == EventPerfectClear)
+	}
+	if !reported {
+		t.Errorf("reported %v, expected a perfect clear", *events)
+	}
+
+	b = newTestBoard(t,
+		"I.........",
+		"IIIIIIIII.",
+	)
+	dropTile(t, b, Red, 9)
+	if b.LastClearWasPerfect() {
+		t.Error("clear that left blocks behind was perfect")
+	}
+}
+
+/*
+ The displayed score is the raw score x100, plus the soft drop points, as the
+ game plays on.
+*/
+func TestDisplayScore(t *testing.T) {
+	b := newTestBoard(t,
+		"I.........",
+		"IIIIIIIII.",
+		"IIIIIIIII.",
+	)
+	check := func() {
+		t.Helper()
+		expected := fmt.Sprintf("%08d", (uint64(b.GetScore())*100)+uint64(b.GetDropPoints()))
+		if display := b.GetDisplayScore(); display != expected {
+			t.Fatalf("displayed score is %s, expected %s", display, expected)
+		}
+	}
+	check()
+	spawnTile(t, b, Red)
+	b.Apply(ActionDown)
+	b.Apply(ActionDown)
+	check()
+	b.DropInColumn(9)
+	b.Next()
+	if b.GetScore() == 0 {
+		t.Fatal("clear did not score")
+	}
+	check()
+}
+
+/*
+ The base score keeps counting past the largest 16-bit number, instead of
+ wrapping back around to 0.
+*/
+func TestScorePast16Bits(t *testing.T) {
+	b := newTestBoard(t,
+		"I.........",
+		"IIIIIIIII.",
+		"IIIIIIIII.",
+		"IIIIIIIII.",
+		"IIIIIIIII.",
+	)
+	b.score = 65530
+	b.lines = 1530
+	level := b.GetLevel()
+	dropTile(t, b, Red, 9)
+	// A Tetris at level 153 scores 8 points per level, counting from 1
+	expected := uint32(65530 + (8 * (uint32(level) + 1)))
+	if (level != 153) || (b.GetScore() != expected) {
+		t.Fatalf("Tetris at level %d scored up to %d, expected level 153 to score up to %d", level, b.GetScore(), expected)
+	}
+	if b.GetScore() != 66762 {
+		t.Errorf("score is %d, expected 66762", b.GetScore())
+	}
+	if b.GetDisplayScore() != "06676200" {
+		t.Errorf("displayed score is %s, expected 06676200", b.GetDisplayScore())
+	}
+}
@@ -0,0 +1,116 @@
+/*
+ * File:        text.go
+ *
+ * Author:      Schuyler Martin <schuylermartin45@gmail.com>
+ *
+ * Description: Human-readable text encoding of a board, for sharing board
+ *              setups in bug reports.
+ */
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+/***** Constants *****/
+
+// Character used for an empty cell in the text encoding
+const textEmptyCell = '.'
+
+/***** Variables *****/
+
+// Each color is encoded as the letter of the tile shape it belongs to.
+var textCellLetters = [Red + 1]byte{
+	Transparent: textEmptyCell,
+	Blue:        'S',
+	Cyan:        'O',
+	Grey:        'T',
+	Yellow:      'L',
+	Green:       'Z',
+	Violet:      'J',
+	Red:         'I',
+}
+
+/***** Functions *****/
+
+/*
+ Constructs a board from the text encoding made by `ExportText()`. The board
+ starts with no dropping tile.
+
+ @param text Text encoding of a board.
+
+ @return The decoded board AND an error if the text could not be decoded.
+*/
+func ImportText(text string) (*Board, error) {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	if len(lines) != int(BoardHeight)+1 {
+		return nil, fmt.Errorf("expected a header and %d rows, got %d lines", BoardHeight, len(lines))
+	}
+
+	b := NewBoard()
+	// The level is derived from the lines, so it is only there for the reader.
+	var level uint8
+	header := strings.TrimSpace(lines[0])
+	if _, err := fmt.Sscanf(header, "score %d level %d lines %d", &b.score, &level, &b.lines); err != nil {
+		return nil, fmt.Errorf("invalid header %q: %v", header, err)
+	}
+
+	for row, line := range lines[1:] {
+		line = strings.TrimSpace(line)
+		if len(line) != int(BoardWidth) {
+			return nil, fmt.Errorf("row %d: expected %d cells, got %d", row, BoardWidth, len(line))
+		}
+		for col := 0; col < len(line); col++ {
+			color, ok := lookupTextCell(line[col])
+			if !ok {
+				return nil, fmt.Errorf("row %d: invalid cell %q", row, line[col])
+			}
+			b.grid[row] |= BoardRow(color) << (rShiftBlockBitDiff - (blockBitSize * BoardRow(col)))
+		}
+	}
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+/***** Internal Functions *****/
+
+/*
+ Looks up the color of a cell in the text encoding.
+
+ @param cell Character encoding the cell.
+
+ @return The cell's color AND true if the character is a valid cell.
+*/
+func lookupTextCell(cell byte) (TileColor, bool) {
+	for color, letter := range textCellLetters {
+		if letter == cell {
+			return TileColor(color), true
+		}
+	}
+	return Transparent, false
+}
+
+/***** Methods *****/
+
+/*
+ Encodes the board as text. The first line is a header with the score, level,
+ and number of cleared rows. Each following line is a row of the board, with
+ '.' for empty cells and the letter of the tile shape for filled ones. The
+ dropping tile is not included.
+
+ @return The text encoding of the board.
+*/
+func (b Board) ExportText() string {
+	var text strings.Builder
+	fmt.Fprintf(&text, "score %d level %d lines %d\n", b.score, b.GetLevel(), b.lines)
+	renderBlocks(func(row uint8, col uint8, isEOL bool, color TileColor) {
+		text.WriteByte(textCellLetters[color])
+		if isEOL {
+			text.WriteByte('\n')
+		}
+	}, b.grid[:BoardHeight], BoardHeight, BoardWidth)
+	return text.String()
+}
@@ -45,7 +45,7 @@ const TileSize = uint8(4)
 type SimpleBlock [TileSize]uint8
 
 // Block is the primitive structure that describes the shape of each tile.
-type Block [TileSize]uint32
+type Block [TileSize]BoardRow
 
 // Tile represents a tile in the game.
 type Tile struct {
@@ -55,6 +55,62 @@ type Tile struct {
 	color TileColor
 }
 
+/***** Variables *****/
+
+// Every tile in the game, in its starting orientation. Tiles follow the Windows
+// 98 Tetris Color scheme.
+var tiles = [7]Tile{
+	// L-left _|
+	buildTile(SimpleBlock{
+		0b00000000,
+		0b00001000,
+		0b00001000,
+		0b00011000,
+	}, Violet),
+	// L-right |_
+	buildTile(SimpleBlock{
+		0b00000000,
+		0b00010000,
+		0b00010000,
+		0b00011000,
+	}, Yellow),
+	// Square
+	buildTile(SimpleBlock{
+		0b00000000,
+		0b00011000,
+		0b00011000,
+		0b00000000,
+	}, Cyan),
+	// Pipe
+	buildTile(SimpleBlock{
+		0b00001000,
+		0b00001000,
+		0b00001000,
+		0b00001000,
+	}, Red),
+	// Tri-point _-_
+	buildTile(SimpleBlock{
+		0b00000000,
+		0b00010000,
+		0b00111000,
+		0b00000000,
+	}, Grey),
+	// S
+	buildTile(SimpleBlock{
+		0b00000000,
+		0b00011000,
+		0b00110000,
+		0b00000000,
+	}, Blue),
+	// Z
+	buildTile(SimpleBlock{
+		0b00000000,
+		0b00110000,
+		0b00011000,
+		0b00000000,
+	}, Green),
+}
+
 /***** Functions *****/
 
 /*
@@ -63,65 +119,30 @@ type Tile struct {
  @param random Reference to a random number generator object.
 */
 func PickTile(random *rand.Rand) *Tile {
-	// Tiles follow the Windows 98 Tetris Color scheme.
-	tiles := [7]Tile{
-		// L-left _|
-		buildTile(SimpleBlock{
-			0b00000000,
-			0b00001000,
-			0b00001000,
-			0b00011000,
-		}, Violet),
-		// L-right |_
-		buildTile(SimpleBlock{
-			0b00000000,
-			0b00010000,
-			0b00010000,
-			0b00011000,
-		}, Yellow),
-		// Square
-		buildTile(SimpleBlock{
-			0b00000000,
-			0b00011000,
-			0b00011000,
-			0b00000000,
-		}, Cyan),
-		// Pipe
-		buildTile(SimpleBlock{
-			0b00001000,
-			0b00001000,
-			0b00001000,
-			0b00001000,
-		}, Red),
-		// Tri-point _-_
-		buildTile(SimpleBlock{
-			0b00000000,
-			0b00010000,
-			0b00111000,
-			0b00000000,
-		}, Grey),
-		// S
-		buildTile(SimpleBlock{
-			0b00000000,
-			0b00011000,
-			0b00110000,
-			0b00000000,
-		}, Blue),
-		// Z
-		buildTile(SimpleBlock{
-			0b00000000,
-			0b00110000,
-			0b00011000,
-			0b00000000,
-		}, Green),
+	tile := tiles[random.Intn(len(tiles))]
+	return &tile
+}
+
+/*
+ Looks up a tile by its identifying color.
+
+ @param color Color of the tile.
+
+ @return The tile, in its starting orientation, AND false if no tile has the
+         color.
+*/
+func lookupTile(color TileColor) (Tile, bool) {
+	for _, tile := range tiles {
+		if tile.color == color {
+			return tile, true
+		}
 	}
-	// Note to self: this is legit in Go even if it feels so wrong.
-	return &tiles[random.Intn(len(tiles))]
+	return Tile{}, false
 }
 
 /*
- Converts from the old 8-bit based grid system to the 32-bit color one (so the
- tiles can be visibly "drawn" in their binary form.
+ Converts from the old 8-bit based grid system to the color one (so the tiles
+ can be visibly "drawn" in their binary form.
 
  @param shape Old shape, 8-bit representation
  @param color 3-bit color code
@@ -132,15 +153,16 @@ func buildTile(shape SimpleBlock, color TileColor) Tile {
 		if shape[row] != 0 {
 			var mask uint8 = 1 << 7
 			for col := uint8(0); col < 8; col++ {
-				tempRow := uint32(0)
+				tempRow := BoardRow(0)
 				if (shape[row] & mask) > 0 {
 					// Initialize with the color, set on the left-hand side
 					// of the board, minding the spare right-most bit.
 					//
-					// << 28 gets leading bit to first position, minus left
-					// 1 bit pad, - blockBitSize to include the new left-most
-					// column that didn't exist in the original 8-column version
-					tempRow = uint32(color) << (rShiftBlockBitDiff - blockBitSize)
+					// `rShiftBlockBitDiff` gets leading bit to first position,
+					// minus left pad, - blockBitSize to include the new
+					// left-most column that didn't exist in the original
+					// 8-column version
+					tempRow = BoardRow(color) << (rShiftBlockBitDiff - blockBitSize)
 					// Project the color in the new board dimensions.
 					tempRow >>= col * uint8(blockBitSize)
 				}
@@ -166,8 +188,8 @@ func (t *Tile) MoveX(direction XDirection) {
 	// Check the bounds. If the left-most or right-most bit is set in any column,
 	// then we can no longer move in that direction.
 	const (
-		leftBoundMask  uint32 = 0xF0000000 // 1 leading unused bit + 1 block
-		rightBoundMask uint32 = 0x0000000F // 1 trailing unused bit + 1 block
+		leftBoundMask  BoardRow = blockMask << rShiftBlockBitDiff // Left-most block
+		rightBoundMask BoardRow = blockMask << 1                  // Right-most block
 	)
 	for row := 0; row < len(t.shape); row++ {
 		if (direction == Left) && (t.shape[row]&leftBoundMask) > 0 {
@@ -191,47 +213,53 @@ func (t *Tile) MoveX(direction XDirection) {
 /*
  Rotates the tile by 90 degrees.
 
- @return True if the rotation occurred. False otherwise.
+ @return True if the rotation occurred. False otherwise, i.e. if the tile has no
+         blocks or is wider than `TileSize`.
 */
 func (t *Tile) Rotate() bool {
-	// Short-circuit on the square tile. No rotation is required.
-	if t.color == Cyan {
-		return true
+	// A tile without blocks (i.e. before the first tile is picked) can't turn,
+	// nor can a tile too wide to stand upright in its block structure.
+	_, minCol, height, width := t.BoundingBox()
+	if (height == 0) || (width > TileSize) {
+		return false
 	}
 	// Generate a repeating color mask to make it easier to copy the color
 	// into the transposed matrix.
-	colorMask := uint32(0)
-	for col := uint32(0); col < 10; col++ {
-		colorMask |= uint32(t.color) << ((blockBitSize * col) + 1)
+	colorMask := BoardRow(0)
+	for col := BoardRow(0); col < BoardRow(BoardWidth); col++ {
+		colorMask |= BoardRow(t.color) << ((blockBitSize * col) + 1)
 	}
 
-	// Find all of the positions in the board that are currently filled. Track
-	// the minimum column value as that minimum column becomes the first row.
+	// Find all of the positions in the board that are currently filled. The
+	// minimum column value becomes the first row.
 	var rowIdxs []uint8
 	var colIdxs []uint8
-	minCol := BoardWidth
 	avgCol := uint8(0)
 	for row := uint8(0); row < TileSize; row++ {
-		var mask uint32 = blockMask << rShiftBlockBitDiff
+		var mask BoardRow = blockMask << rShiftBlockBitDiff
 		for col := uint8(0); col < BoardWidth; col++ {
-			if uint32(t.shape[row]&mask) > 0 {
+			if (t.shape[row] & mask) > 0 {
 				rowIdxs = append(rowIdxs, row)
 				colIdxs = append(colIdxs, col)
 				avgCol += col
-				if col < minCol {
-					minCol = col
-				}
 			}
 			mask >>= blockBitSize
 		}
 	}
-	avgCol /= TileSize
+	// Short-circuit on filled 2x2 squares, which look the same in every
+	// rotation. The shape is checked instead of the color, so custom tiles
+	// rotate correctly.
+	if (height == 2) && (width == 2) && (len(colIdxs) == 4) {
+		return true
+	}
+	// Custom tiles may have more or fewer blocks than the standard tiles
+	avgCol /= uint8(len(colIdxs))
 	// Iterate over all known block positions, re-adjusting the coordinates
 	// as blocks are examined. Block will appear rotated on the far-right-side
 	// of the board.
 	transpose := Block{}
-	for i := uint8(0); i < TileSize; i++ {
-		transposeMask := uint32(blockMask << ((blockBitSize * uint32(rowIdxs[i])) + 1))
+	for i := range rowIdxs {
+		transposeMask := BoardRow(blockMask << ((blockBitSize * BoardRow(rowIdxs[i])) + 1))
 		transpose[colIdxs[i]-minCol] |= transposeMask & colorMask
 	}
 	t.shape = transpose
@@ -244,6 +272,18 @@ func (t *Tile) Rotate() bool {
 	return true
 }
 
+/*
+ Compares two tiles.
+
+ @param other Tile to compare against.
+
+ @return True if both tiles have the same shape, in the same position, and the
+         same color. False otherwise.
+*/
+func (t Tile) Equals(other Tile) bool {
+	return (t.shape == other.shape) && (t.color == other.color)
+}
+
 /*
  Get the color of the tile
 
@@ -258,10 +298,62 @@ func (t Tile) GetColor() TileColor {
 
  @return The tile's shape.
 */
-func (t Tile) GetBlock() []uint32 {
+func (t Tile) GetBlock() []BoardRow {
 	return t.shape[:]
 }
 
+/*
+ Get the region of the tile's block structure that is occupied by blocks.
+
+ @return The top row and left-most column of the occupied region, followed by
+         the region's height and width. All zeros if the tile has no blocks.
+*/
+func (t Tile) BoundingBox() (topRow uint8, leftCol uint8, height uint8, width uint8) {
+	minRow, maxRow := TileSize, uint8(0)
+	minCol, maxCol := BoardWidth, uint8(0)
+	for row := uint8(0); row < TileSize; row++ {
+		var mask BoardRow = blockMask << rShiftBlockBitDiff
+		for col := uint8(0); col < BoardWidth; col++ {
+			if (t.shape[row] & mask) > 0 {
+				if row < minRow {
+					minRow = row
+				}
+				if row > maxRow {
+					maxRow = row
+				}
+				if col < minCol {
+					minCol = col
+				}
+				if col > maxCol {
+					maxCol = col
+				}
+			}
+			mask >>= blockBitSize
+		}
+	}
+	// No blocks were found
+	if minRow > maxRow {
+		return 0, 0, 0, 0
+	}
+	return minRow, minCol, (maxRow - minRow) + 1, (maxCol - minCol) + 1
+}
+
+/*
+ Get the size of the gap from the top of the tile's block to the top of the
+ physical tile. In other words, this is the count of zero-rows at the start of
+ the tile.
+
+ @return Number of empty rows above the tile in the tile's block structure.
+*/
+func (t Tile) GetTopGap() uint8 {
+	topRow, _, height, _ := t.BoundingBox()
+	// A tile without blocks is all gap
+	if height == 0 {
+		return TileSize
+	}
+	return topRow
+}
+
 /*
  Get the size of the gap from the bottom of the physical tile to the end
  of the tile's block. In other words, this is the count of zero-rows at the end
@@ -270,13 +362,21 @@ func (t Tile) GetBlock() []uint32 {
  @return Number of empty rows under the tile in the tile's block structure.
 */
 func (t Tile) GetBottomGap() uint8 {
-	var cntr uint8 = 0
-	for row := len(t.shape) - 1; row >= 0; row-- {
-		if t.shape[row] == 0 {
-			cntr++
-		} else {
-			return cntr
-		}
+	topRow, _, height, _ := t.BoundingBox()
+	// A tile without blocks is all gap
+	if height == 0 {
+		return TileSize
 	}
-	return cntr
+	return TileSize - (topRow + height)
+}
+
+/*
+ Given a callback, this function iterates over the tile and executes the callback
+ to render a block, the same way the next tile is previewed.
+
+ @param draw Callback to draw a block at a row, column position with a specific
+             color.
+*/
+func (t Tile) Render(draw DrawBlock) {
+	renderBlocks(draw, t.shape[:], TileSize, BoardWidth-2)
 }
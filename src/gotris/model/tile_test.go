@@ -0,0 +1,224 @@
+/*
+ * File:        tile_test.go
+ *
+ * Author:      Schuyler Martin <schuylermartin45@gmail.com>
+ *
+ * Description: Tests for tiles.
+ */
+package model
+
+import (
+	"testing"
+)
+
+/***** Tests *****/
+
+/*
+ A tile without blocks, like the next tile before the first one is picked, does
+ not rotate.
+*/
+func TestRotateEmptyTile(t *testing.T) {
+	b := NewBoard()
+	tile := b.GetNextTile()
+	if tile.Rotate() {
+		t.Error("rotated a tile without blocks")
+	}
+	if !tile.Equals(Tile{}) {
+		t.Error("rotating changed a tile without blocks")
+	}
+}
+
+/*
+ Tiles with more or fewer blocks than the standard tiles rotate without losing
+ blocks.
+*/
+func TestRotateCustomBlockCount(t *testing.T) {
+	shapes := map[string]SimpleBlock{
+		"tromino": {
+			0b00000000,
+			0b00111000,
+			0b00000000,
+			0b00000000,
+		},
+		"pentomino": {
+			0b00000000,
+			0b00011000,
+			0b00011000,
+			0b00010000,
+		},
+		"corner": {
+			0b00000000,
+			0b00011000,
+			0b00010000,
+			0b00000000,
+		},
+	}
+	for name, shape := range shapes {
+		tile := buildTile(shape, Grey)
+		_, _, height, width := tile.BoundingBox()
+		blocks := countTileBlocks(tile)
+		if !tile.Rotate() {
+			t.Fatalf("%s did not rotate", name)
+		}
+		if _, _, turnedHeight, turnedWidth := tile.BoundingBox(); (turnedHeight != width) || (turnedWidth != height) {
+			t.Errorf("%s turned from %dx%d to %dx%d", name, height, width, turnedHeight, turnedWidth)
+		}
+		if turned := countTileBlocks(tile); turned != blocks {
+			t.Errorf("%s has %d blocks after rotating, expected %d", name, turned, blocks)
+		}
+	}
+}
+
+/*
+ Custom tiles that use the square's color, or fit in a 2x2 box, but aren't
+ square, still rotate.
+*/
+func TestRotateNonSquareCyan(t *testing.T) {
+	type turn struct {
+		shape  SimpleBlock
+		height uint8
+		width  uint8
+	}
+	shapes := map[string]turn{
+		"tall": {SimpleBlock{
+			0b00000000,
+			0b00011000,
+			0b00010000,
+			0b00010000,
+		}, 2, 3},
+		"corner": {SimpleBlock{
+			0b00000000,
+			0b00011000,
+			0b00010000,
+			0b00000000,
+		}, 2, 2},
+	}
+	for name, shape := range shapes {
+		tile := buildTile(shape.shape, Cyan)
+		before := tile
+		if !tile.Rotate() {
+			t.Fatalf("%s did not rotate", name)
+		}
+		// Moving the blocks without turning them would not change the outline
+		if tileOutline(tile) == tileOutline(before) {
+			t.Fatalf("%s kept its shape", name)
+		}
+		if _, _, height, width := tile.BoundingBox(); (height != shape.height) || (width != shape.width) {
+			t.Errorf("%s turned to %dx%d, expected %dx%d", name, height, width, shape.height, shape.width)
+		}
+	}
+}
+
+/*
+ The square looks the same in every rotation.
+*/
+func TestRotateSquare(t *testing.T) {
+	tile, _ := lookupTile(Cyan)
+	before := tile
+	if !tile.Rotate() {
+		t.Fatal("square did not rotate")
+	}
+	if !tile.Equals(before) {
+		t.Error("square changed shape")
+	}
+}
+
+/*
+ The bounding box of every tile covers its blocks in its starting orientation,
+ and turns with the tile.
+*/
+func TestBoundingBox(t *testing.T) {
+	type box struct{ topRow, leftCol, height, width uint8 }
+	cases := map[TileColor]box{
+		Blue:   {1, 3, 2, 3},
+		Cyan:   {1, 4, 2, 2},
+		Grey:   {1, 3, 2, 3},
+		Yellow: {1, 4, 3, 2},
+		Green:  {1, 3, 2, 3},
+		Violet: {1, 4, 3, 2},
+		Red:    {0, 5, 4, 1},
+	}
+	for color, expected := range cases {
+		tile, _ := lookupTile(color)
+		topRow, leftCol, height, width := tile.BoundingBox()
+		if actual := (box{topRow, leftCol, height, width}); actual != expected {
+			t.Errorf("tile %d has bounding box %+v, expected %+v", color, actual, expected)
+		}
+	}
+	rotated := map[TileColor]box{
+		Yellow: {0, 3, 2, 3},
+		Red:    {0, 3, 1, 4},
+	}
+	for color, expected := range rotated {
+		tile, _ := lookupTile(color)
+		tile.Rotate()
+		topRow, leftCol, height, width := tile.BoundingBox()
+		if actual := (box{topRow, leftCol, height, width}); actual != expected {
+			t.Errorf("rotated tile %d has bounding box %+v, expected %+v", color, actual, expected)
+		}
+	}
+}
+
+/*
+ The top gap counts the empty rows above every tile's blocks.
+*/
+func TestGetTopGap(t *testing.T) {
+	cases := map[TileColor]uint8{
+		Blue:   1,
+		Cyan:   1,
+		Grey:   1,
+		Yellow: 1,
+		Green:  1,
+		Violet: 1,
+		Red:    0,
+	}
+	for color, expected := range cases {
+		tile, _ := lookupTile(color)
+		if gap := tile.GetTopGap(); gap != expected {
+			t.Errorf("tile %d has a top gap of %d, expected %d", color, gap, expected)
+		}
+	}
+	if gap := (Tile{}).GetTopGap(); gap != TileSize {
+		t.Errorf("tile without blocks has a top gap of %d, expected %d", gap, TileSize)
+	}
+}
+
+/***** Internal Functions *****/
+
+/*
+ Counts the blocks in a tile.
+
+ @param tile Tile to count.
+
+ @return The number of blocks in the tile.
+*/
+func countTileBlocks(tile Tile) int {
+	count := 0
+	for _, row := range tile.GetBlock() {
+		for col := uint8(0); col < BoardWidth; col++ {
+			if getBlock(row, col) != Transparent {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+/*
+ Gets the outline of a tile's blocks, wherever they are in its block structure.
+
+ @param tile Tile to outline.
+
+ @return Which cells of the tile's bounding box are filled, with the bounding
+         box moved to the top left.
+*/
+func tileOutline(tile Tile) [TileSize][TileSize]bool {
+	var outline [TileSize][TileSize]bool
+	topRow, leftCol, height, width := tile.BoundingBox()
+	for row := uint8(0); row < height; row++ {
+		for col := uint8(0); col < width; col++ {
+			outline[row][col] = getBlock(tile.shape[topRow+row], leftCol+col) != Transparent
+		}
+	}
+	return outline
+}
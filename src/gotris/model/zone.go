@@ -0,0 +1,110 @@
+/*
+ * File:        zone.go
+ *
+ * Author:      Schuyler Martin <schuylermartin45@gmail.com>
+ *
+ * Description: The zone, where gravity stops and cleared rows are banked at the
+ *              bottom of the board, to be released all at once for a bonus.
+ */
+package model
+
+/***** Constants *****/
+
+// Base points scored for every row released from the zone. A Tetris scores 2
+// points a row, so banking rows in the zone pays off.
+const zoneRowPoints = uint32(3)
+
+/***** Methods *****/
+
+/*
+ Enters the zone. While in the zone, tiles only fall when they are dropped and
+ filled rows are not cleared. Instead, they sink to the bottom of the board,
+ where they are banked until the zone is exited. Garbage waits in the queue
+ until then, so it never rises under the banked rows.
+*/
+func (b *Board) EnterZone() {
+	b.inZone = true
+}
+
+/*
+ Exits the zone, clearing every banked row at once. Every banked row scores
+ `zoneRowPoints`, multiplied by the level (counting from 1). Like any other
+ clear, releasing four or more rows is a Tetris, and emptying the board is a
+ perfect clear. Queued garbage is not cancelled by the release.
+
+ @return The number of rows released. 0 if the board was not in the zone or no
+         rows were banked.
+*/
+func (b *Board) ExitZone() uint8 {
+	if !b.inZone {
+		return 0
+	}
+	b.inZone = false
+	banked := b.zoneLines
+	b.zoneLines = 0
+	if banked == 0 {
+		return 0
+	}
+	prevLevel := b.GetLevel()
+	prevScore := b.score
+	b.clearedCells = nil
+	for row := BoardHeight - banked; row < BoardHeight; row++ {
+		for col := uint8(0); col < BoardWidth; col++ {
+			b.clearedCells = append(b.clearedCells, ClearedCell{Row: row, Col: col, Color: getBlock(b.grid[row], col)})
+		}
+	}
+	// Everything above the banked rows falls onto the floor
+	for row := BoardHeight - 1; row >= banked; row-- {
+		b.grid[row] = b.grid[row-banked]
+	}
+	for row := uint8(0); row < banked; row++ {
+		b.grid[row] = maskRow2BitPad
+	}
+	b.score += zoneRowPoints * uint32(banked) * (uint32(prevLevel) + 1)
+	// The release is scored on its own, not with the last iteration
+	b.scoreDelta = 0
+	b.scoreClearedRows(&b.grid, uint16(banked), banked >= TileSize, prevLevel, prevScore)
+	return banked
+}
+
+/*
+ Checks if the board is in the zone.
+
+ @return True if the board is in the zone. False otherwise.
+*/
+func (b Board) InZone() bool {
+	return b.inZone
+}
+
+/*
+ Get the number of rows banked at the bottom of the board in the zone, so views
+ can show how big the release will be.
+
+ @return The number of banked rows. 0 outside of the zone.
+*/
+func (b Board) ZoneLines() uint8 {
+	return b.zoneLines
+}
+
+/***** Internal Methods *****/
+
+/*
+ Sinks the filled rows of a grid to the bottom of the board, on top of the rows
+ already banked in the zone. Rows that are not filled keep their order above the
+ banked rows.
+
+ @param grid Grid the tile locked into.
+*/
+func (b *Board) bankRows(grid *BoardGrid) {
+	top := BoardHeight - b.zoneLines
+	var filled, rest []BoardRow
+	for row := uint8(0); row < top; row++ {
+		if calcCollisionRow(grid[row]) == maskFullRow {
+			filled = append(filled, grid[row])
+		} else {
+			rest = append(rest, grid[row])
+		}
+	}
+	copy(grid[:top], append(rest, filled...))
+	b.zoneLines += uint8(len(filled))
+}
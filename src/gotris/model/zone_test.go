@@ -0,0 +1,197 @@
+/*
+ * File:        zone_test.go
+ *
+ * Author:      Schuyler Martin <schuylermartin45@gmail.com>
+ *
+ * Description: Tests for banking rows in the zone.
+ */
+package model
+
+import (
+	"testing"
+)
+
+/***** Internal Functions *****/
+
+/*
+ Constructs a board in the zone with two rows banked at the bottom. The rows
+ that were not filled are left above the banked rows.
+
+ @param t Test the board is for.
+
+ @return The board.
+*/
+func newZoneBoard(t *testing.T) *Board {
+	t.Helper()
+	b := newTestBoard(t,
+		"IIIIIIIII.",
+		"IIIIIIIII.",
+		"I.........",
+	)
+	b.EnterZone()
+	dropTile(t, b, Red, 9)
+	if b.ZoneLines() != 2 {
+		t.Fatalf("banked %d rows, expected 2", b.ZoneLines())
+	}
+	checkBottomRows(t, b,
+		".........I",
+		"I........I",
+		"IIIIIIIIII",
+		"IIIIIIIIII",
+	)
+	return b
+}
+
+/***** Tests *****/
+
+/*
+ Filled rows sink to the bottom of the board in the zone, and are cleared for a
+ bonus when the zone is exited.
+*/
+func TestZoneBanksAndReleasesRows(t *testing.T) {
+	b := newZoneBoard(t)
+	if (b.GetLines() != 0) || (b.GetScore() != 0) {
+		t.Fatalf("banking scored %d points and %d lines", b.GetScore(), b.GetLines())
+	}
+	if released := b.ExitZone(); released != 2 {
+		t.Fatalf("released %d rows, expected 2", released)
+	}
+	if b.InZone() || (b.ZoneLines() != 0) {
+		t.Fatal("board is still in the zone")
+	}
+	checkBottomRows(t, b,
+		"..........",
+		".........I",
+		"I........I",
+	)
+	if expected := 2 * zoneRowPoints; b.GetScore() != expected {
+		t.Errorf("score is %d, expected %d", b.GetScore(), expected)
+	}
+	if b.GetLines() != 2 {
+		t.Errorf("cleared %d lines, expected 2", b.GetLines())
+	}
+	if b.LastScoreDelta() != 2*zoneRowPoints {
+		t.Errorf("score delta is %d, expected %d", b.LastScoreDelta(), 2*zoneRowPoints)
+	}
+}
+
+/*
+ Rows banked by several locks are all released at once.
+*/
+func TestZoneBanksSeveralClears(t *testing.T) {
+	b := newTestBoard(t,
+		"IIIIIIII..",
+		"IIIIIIII..",
+		"IIIIIIIII.",
+		"IIIIIIIII.",
+	)
+	b.EnterZone()
+	dropTile(t, b, Red, 9)
+	dropTile(t, b, Red, 8)
+	if b.ZoneLines() != 4 {
+		t.Fatalf("banked %d rows, expected 4", b.ZoneLines())
+	}
+	if released := b.ExitZone(); released != 4 {
+		t.Fatalf("released %d rows, expected 4", released)
+	}
+	checkBottomRows(t, b,
+		"..........",
+		"........I.",
+		"........I.",
+	)
+	if expected := 4 * zoneRowPoints; b.GetScore() != expected {
+		t.Errorf("score is %d, expected %d", b.GetScore(), expected)
+	}
+}
+
+/*
+ Releasing four banked rows that empty the board is a Tetris and a perfect
+ clear, and scores the perfect clear bonus on top of the release.
+*/
+func TestZoneReleasePerfectTetris(t *testing.T) {
+	b := newTestBoard(t,
+		"IIIIIIIII.",
+		"IIIIIIIII.",
+		"IIIIIIIII.",
+		"IIIIIIIII.",
+	)
+	b.EnterZone()
+	dropTile(t, b, Red, 9)
+	events := recordEvents(b)
+	if released := b.ExitZone(); released != 4 {
+		t.Fatalf("released %d rows, expected 4", released)
+	}
+	expected := []Event{EventLinesCleared, EventTetris, EventPerfectClear}
+	if len(*events) != len(expected) {
+		t.Fatalf("reported %v, expected %v", *events, expected)
+	}
+	for i := range expected {
+		if (*events)[i] != expected[i] {
+			t.Fatalf("reported %v, expected %v", *events, expected)
+		}
+	}
+	if !b.LastClearWasPerfect() {
+		t.Error("release was not a perfect clear")
+	}
+	points := (4 * zoneRowPoints) + uint32(perfectClearBonus[TileSize])
+	if (b.GetScore() != points) || (b.LastScoreDelta() != points) {
+		t.Errorf("scored %d with a delta of %d, expected %d", b.GetScore(), b.LastScoreDelta(), points)
+	}
+}
+
+/*
+ Garbage waits in the queue while rows are banked, so exiting the zone releases
+ the banked rows and not the garbage.
+*/
+func TestZoneDefersGarbage(t *testing.T) {
+	b := newZoneBoard(t)
+	b.QueueGarbage(1)
+	if !b.AddGarbageLines(1) {
+		t.Fatal("garbage did not fit")
+	}
+	// The next spawn would normally raise queued garbage
+	spawnTile(t, b, Cyan)
+	if b.PendingGarbage() != 2 {
+		t.Fatalf("%d rows of garbage are queued, expected 2", b.PendingGarbage())
+	}
+	checkBottomRows(t, b,
+		".........I",
+		"I........I",
+		"IIIIIIIIII",
+		"IIIIIIIIII",
+	)
+	if released := b.ExitZone(); released != 2 {
+		t.Fatalf("released %d rows, expected 2", released)
+	}
+	checkBottomRows(t, b,
+		".........I",
+		"I........I",
+	)
+	if b.GetLines() != 2 {
+		t.Errorf("cleared %d lines, expected 2", b.GetLines())
+	}
+	if b.PendingGarbage() != 2 {
+		t.Errorf("%d rows of garbage are queued, expected 2", b.PendingGarbage())
+	}
+}
+
+/*
+ Shifting the stack down in the zone discards the stack, not the banked rows.
+*/
+func TestZoneShiftKeepsBankedRows(t *testing.T) {
+	b := newZoneBoard(t)
+	b.ShiftDown(1)
+	checkBottomRows(t, b,
+		"..........",
+		".........I",
+		"IIIIIIIIII",
+		"IIIIIIIIII",
+	)
+	if released := b.ExitZone(); released != 2 {
+		t.Fatalf("released %d rows, expected 2", released)
+	}
+	checkBottomRows(t, b,
+		"..........",
+		".........I",
+	)
+}
@@ -0,0 +1,363 @@
+/*
+ * File:        api.go
+ *
+ * Author:      Schuyler Martin <schuylermartin45@gmail.com>
+ *
+ * Description: HTTP control API for the board, so other programs can play
+ *              Gotris over the network. State and actions can also be streamed,
+ *              for renderers and bots that need every frame.
+ */
+package api
+
+import (
+	"encoding/json"
+	"github.com/schuylermartin45/gotris/src/gotris/model"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+/***** Constants *****/
+
+// DefaultAddress is the address the server listens on when none is given.
+const DefaultAddress = ":8080"
+
+// How often the server advances the game. Gravity still follows the board's
+// gravity interval, this is only how often elapsed time is reported to it.
+const tickRate = 20 * time.Millisecond
+
+/***** Types *****/
+
+// NewBoardFunc constructs the board for a new game.
+type NewBoardFunc func() *model.Board
+
+// Server exposes a board over HTTP. Gravity advances on the server, so the game
+// keeps running between requests.
+type Server struct {
+	// Guards every field below. Requests and the ticker run on their own
+	// goroutines.
+	mutex    sync.Mutex
+	board    *model.Board
+	newBoard NewBoardFunc
+	gameOver bool
+	// Streams of `/stream` subscribers, and the last state sent to them. Each
+	// stream holds the latest state, so slow subscribers skip frames instead of
+	// holding up the game.
+	subscribers map[chan state]bool
+	published   state
+}
+
+// actionRequest is the body of a POST to `/action`.
+type actionRequest struct {
+	Action string `json:"action"`
+}
+
+// state is the body returned by every endpoint.
+type state struct {
+	// Board, top row first, as tile colors. Includes the dropping tile.
+	Board    [model.BoardHeight][model.BoardWidth]model.TileColor `json:"board"`
+	Score    uint64                                               `json:"score"`
+	Level    uint8                                                `json:"level"`
+	Lines    uint16                                               `json:"lines"`
+	Next     model.TileColor                                      `json:"next"`
+	GameOver bool                                                 `json:"gameOver"`
+	// Whether the last action moved the tile. Only set by `/action`.
+	Applied bool `json:"applied"`
+}
+
+/***** Variables *****/
+
+// Actions that can be posted, by name
+var actionNames = map[string]model.Action{
+	"left":   model.ActionLeft,
+	"right":  model.ActionRight,
+	"down":   model.ActionDown,
+	"drop":   model.ActionFastDown,
+	"rotate": model.ActionRotate,
+}
+
+/***** Functions *****/
+
+/*
+ Constructs a server with a new game.
+
+ @param newBoard Constructs the board for every game, including the first one.
+
+ @return A new server.
+*/
+func NewServer(newBoard NewBoardFunc) *Server {
+	s := new(Server)
+	s.newBoard = newBoard
+	s.board = newBoard()
+	s.subscribers = make(map[chan state]bool)
+	return s
+}
+
+/*
+ Returns a string to display the help menu.
+
+ @return The help menu of the server.
+*/
+func HelpMenu() string {
+	return "Serve Mode\n" +
+		"\nAbout\n" +
+		"  Plays the game over HTTP, so other programs can control it.\n" +
+		"\nEndpoints\n" +
+		"  * GET  /state:  Board, score, level, lines, and next tile as JSON\n" +
+		"  * POST /action: Performs an action, given as {\"action\": \"<name>\"}\n" +
+		"                  Actions: left, right, down, drop, rotate\n" +
+		"  * POST /reset:  Starts a new game\n" +
+		"  * GET  /stream: Streams the state every time it changes, one JSON\n" +
+		"                  object per line\n" +
+		"  * POST /actions: Performs a stream of actions as they arrive, one\n" +
+		"                  {\"action\": \"<name>\"} per line\n" +
+		"\nEvery endpoint responds with the state of the game. Cells of the\n" +
+		"board are tile colors, with 0 being an empty cell.\n"
+}
+
+/*
+ Writes a JSON response.
+
+ @param w     Response to write to.
+ @param code  HTTP status code.
+ @param value Value to encode as the body.
+*/
+func writeJSON(w http.ResponseWriter, code int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(value)
+}
+
+/***** Methods *****/
+
+/*
+ Constructs the request handler for the API's endpoints.
+
+ @return The API's request handler.
+*/
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/state", s.handleState)
+	mux.HandleFunc("/action", s.handleAction)
+	mux.HandleFunc("/reset", s.handleReset)
+	mux.HandleFunc("/stream", s.handleStream)
+	mux.HandleFunc("/actions", s.handleActions)
+	return mux
+}
+
+/*
+ Runs the game and serves the API until the server fails.
+
+ @param addr Address to listen on, i.e. `:8080`.
+
+ @return The error that stopped the server.
+*/
+func (s *Server) ListenAndServe(addr string) error {
+	ticker := time.NewTicker(tickRate)
+	defer ticker.Stop()
+	go s.runGravity(ticker)
+	return http.ListenAndServe(addr, s.Handler())
+}
+
+/** Internal **/
+
+/*
+ Advances the game every time the ticker fires. The game stays over until it is
+ reset.
+
+ @param ticker Ticker that paces the game.
+*/
+func (s *Server) runGravity(ticker *time.Ticker) {
+	lastTick := time.Now()
+	for now := range ticker.C {
+		s.mutex.Lock()
+		if !s.gameOver {
+			_, s.gameOver = s.board.Tick(now.Sub(lastTick))
+		}
+		s.publish()
+		s.mutex.Unlock()
+		lastTick = now
+	}
+}
+
+/*
+ Captures the state of the game. The caller must hold the mutex.
+
+ @return The current state of the game.
+*/
+func (s *Server) snapshot() state {
+	var current state
+	s.board.RenderBoard(func(row uint8, col uint8, isEOL bool, color model.TileColor) {
+		current.Board[row][col] = color
+	})
+	current.Score = (uint64(s.board.GetScore()) * 100) + uint64(s.board.GetDropPoints())
+	current.Level = s.board.GetLevel()
+	current.Lines = s.board.GetLines()
+	current.Next = s.board.GetNextTile().GetColor()
+	current.GameOver = s.gameOver
+	return current
+}
+
+/*
+ Sends the state of the game to every `/stream` subscriber, if it changed since
+ it was last sent. The caller must hold the mutex.
+*/
+func (s *Server) publish() {
+	current := s.snapshot()
+	if current == s.published {
+		return
+	}
+	s.published = current
+	for stream := range s.subscribers {
+		// Replace a state the subscriber has yet to read with the latest one
+		select {
+		case stream <- current:
+		default:
+			select {
+			case <-stream:
+			default:
+			}
+			stream <- current
+		}
+	}
+}
+
+/*
+ Performs an action on the board. The caller must hold the mutex.
+
+ @param name Name of the action.
+
+ @return The state of the game after the action AND false if no action has the
+         name.
+*/
+func (s *Server) apply(name string) (state, bool) {
+	action, ok := actionNames[name]
+	if !ok {
+		return state{}, false
+	}
+	applied := false
+	if !s.gameOver {
+		applied = s.board.Apply(action)
+	}
+	s.publish()
+	current := s.snapshot()
+	current.Applied = applied
+	return current, true
+}
+
+// handleState responds to `GET /state` with the state of the game.
+func (s *Server) handleState(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	writeJSON(w, http.StatusOK, s.snapshot())
+}
+
+// handleAction responds to `POST /action` by performing an action on the board.
+func (s *Server) handleAction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var request actionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	current, ok := s.apply(request.Action)
+	if !ok {
+		http.Error(w, "unknown action: "+request.Action, http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusOK, current)
+}
+
+// handleReset responds to `POST /reset` by starting a new game.
+func (s *Server) handleReset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.board = s.newBoard()
+	s.gameOver = false
+	s.publish()
+	writeJSON(w, http.StatusOK, s.snapshot())
+}
+
+// handleStream responds to `GET /stream` by sending the state of the game every
+// time it changes, until the client disconnects.
+func (s *Server) handleStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	stream := make(chan state, 1)
+	s.mutex.Lock()
+	s.subscribers[stream] = true
+	stream <- s.snapshot()
+	s.mutex.Unlock()
+	defer func() {
+		s.mutex.Lock()
+		delete(s.subscribers, stream)
+		s.mutex.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	encoder := json.NewEncoder(w)
+	for {
+		select {
+		case current := <-stream:
+			if err := encoder.Encode(current); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// handleActions responds to `POST /actions` by performing every action in the
+// body as soon as it arrives. Responds with the state after the last action.
+func (s *Server) handleActions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	s.mutex.Lock()
+	current := s.snapshot()
+	s.mutex.Unlock()
+	for {
+		var request actionRequest
+		if err := decoder.Decode(&request); err == io.EOF {
+			break
+		} else if err != nil {
+			http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.mutex.Lock()
+		applied, ok := s.apply(request.Action)
+		s.mutex.Unlock()
+		if !ok {
+			http.Error(w, "unknown action: "+request.Action, http.StatusBadRequest)
+			return
+		}
+		current = applied
+	}
+	writeJSON(w, http.StatusOK, current)
+}
@@ -0,0 +1,140 @@
+/*
+ * File:        api_test.go
+ *
+ * Author:      Schuyler Martin <schuylermartin45@gmail.com>
+ *
+ * Description: Tests for streaming the game over the HTTP API.
+ */
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"github.com/schuylermartin45/gotris/src/gotris/model"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+/***** Tests *****/
+
+/*
+ Actions streamed to `/actions` are performed in order, and subscribers of
+ `/stream` receive the state they leave the game in.
+*/
+func TestStreamActions(t *testing.T) {
+	s := NewServer(newTestBoard)
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+
+	stream, err := http.Get(server.URL + "/stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Body.Close()
+	frames := bufio.NewScanner(stream.Body)
+	initial := readFrame(t, frames)
+
+	body := strings.NewReader("{\"action\": \"left\"}\n{\"action\": \"left\"}\n")
+	response, err := http.Post(server.URL+"/actions", "application/x-ndjson", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("streaming actions responded %d", response.StatusCode)
+	}
+	var final state
+	if err := json.NewDecoder(response.Body).Decode(&final); err != nil {
+		t.Fatal(err)
+	}
+	if !final.Applied {
+		t.Fatal("last streamed action did not move the tile")
+	}
+	if final.Board == initial.Board {
+		t.Fatal("streamed actions did not change the board")
+	}
+
+	// Slow subscribers skip frames, but always end on the latest state
+	for frame := readFrame(t, frames); frame.Board != final.Board; {
+		frame = readFrame(t, frames)
+	}
+}
+
+/*
+ An unknown action in a stream stops the stream with an error.
+*/
+func TestStreamUnknownAction(t *testing.T) {
+	s := NewServer(newTestBoard)
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+
+	body := strings.NewReader("{\"action\": \"left\"}\n{\"action\": \"jump\"}\n")
+	response, err := http.Post(server.URL+"/actions", "application/x-ndjson", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusBadRequest {
+		t.Errorf("unknown action responded %d, expected %d", response.StatusCode, http.StatusBadRequest)
+	}
+}
+
+/*
+ The score in the state matches the displayed score, so soft drops count.
+*/
+func TestStateScoreCountsDrops(t *testing.T) {
+	s := NewServer(newTestBoard)
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+
+	body := strings.NewReader("{\"action\": \"down\"}\n{\"action\": \"down\"}\n")
+	response, err := http.Post(server.URL+"/actions", "application/x-ndjson", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	var final state
+	if err := json.NewDecoder(response.Body).Decode(&final); err != nil {
+		t.Fatal(err)
+	}
+	if final.Score != 2 {
+		t.Errorf("score is %d after two soft drops, expected 2", final.Score)
+	}
+}
+
+/***** Internal Functions *****/
+
+/*
+ Constructs a seeded board with a tile already dropping, since the tests don't
+ run gravity to spawn one.
+
+ @return The board.
+*/
+func newTestBoard() *model.Board {
+	b := model.NewBoardWithSeed(0)
+	b.SetDelays(0, 0)
+	b.Next()
+	return b
+}
+
+/*
+ Reads the next state from a `/stream` response.
+
+ @param t      Test doing the read.
+ @param frames Lines of the response.
+
+ @return The state.
+*/
+func readFrame(t *testing.T, frames *bufio.Scanner) state {
+	t.Helper()
+	if !frames.Scan() {
+		t.Fatalf("stream ended: %v", frames.Err())
+	}
+	var frame state
+	if err := json.Unmarshal(frames.Bytes(), &frame); err != nil {
+		t.Fatal(err)
+	}
+	return frame
+}
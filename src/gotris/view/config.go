@@ -0,0 +1,151 @@
+/*
+ * File:        config.go
+ *
+ * Author:      Schuyler Martin <schuylermartin45@gmail.com>
+ *
+ * Description: Player preferences, saved between launches so they don't have to
+ *              be picked on the command line every time.
+ */
+package view
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"unicode/utf8"
+)
+
+/***** Types *****/
+
+// Config holds a player's preferences. Unset fields keep their defaults.
+type Config struct {
+	// Render mode played when none is given
+	Mode string `json:"mode,omitempty"`
+	// Rules to play by. One of `GameModeNames`.
+	GameMode string `json:"gameMode,omitempty"`
+	// Color scheme of the text mode
+	Scheme string `json:"scheme,omitempty"`
+	// Level to start at
+	Level uint8 `json:"level,omitempty"`
+	// Character blocks are drawn with in the text mode
+	Glyph string `json:"glyph,omitempty"`
+	// Whether the text mode plays effects. Nil keeps the default.
+	Effects *bool `json:"effects,omitempty"`
+	// Initials high scores are recorded under
+	Initials string `json:"initials,omitempty"`
+	// Extra keys of the text mode, mapping a character to the name of an action
+	// (i.e. "j": "left")
+	Keys map[string]string `json:"keys,omitempty"`
+}
+
+/***** Variables *****/
+
+// GameModeNames are the names of the rules a game can be played by
+var GameModeNames = []string{"classic", "zen", "survival"}
+
+// Actions that keys can be bound to, by name
+var keyActionNames = map[string]Action{
+	"left":       ActionLeft,
+	"right":      ActionRight,
+	"down":       ActionDown,
+	"drop":       ActionFastDown,
+	"rotate":     ActionRotate,
+	"exit":       ActionExit,
+	"screenshot": ActionScreenshot,
+}
+
+/***** Functions *****/
+
+/*
+ Get the default location of the preferences file, in the user's configuration
+ directory (i.e. `~/.config/gotris/config.json`).
+
+ @return The path of the preferences file AND an error if the user has no
+         configuration directory.
+*/
+func ConfigPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gotris", "config.json"), nil
+}
+
+/*
+ Loads preferences from a file. A missing file is not an error, as players
+ don't need one.
+
+ @param path File to load.
+
+ @return The preferences, empty if the file is missing, AND an error if the file
+         could not be read or is invalid.
+*/
+func LoadConfig(path string) (Config, error) {
+	var config Config
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return config, nil
+	} else if err != nil {
+		return config, err
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return config, fmt.Errorf("%s: %v", path, err)
+	}
+	if _, err := config.KeyBindings(); err != nil {
+		return config, fmt.Errorf("%s: %v", path, err)
+	}
+	if config.GameMode != "" {
+		known := false
+		for _, name := range GameModeNames {
+			known = known || (config.GameMode == name)
+		}
+		if !known {
+			return config, fmt.Errorf("%s: unknown game mode %q", path, config.GameMode)
+		}
+	}
+	return config, nil
+}
+
+/*
+ Saves preferences to a file, creating its directory if needed.
+
+ @param path   File to save to.
+ @param config Preferences to save.
+
+ @return An error if the file could not be written.
+*/
+func SaveConfig(path string, config Config) error {
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+/***** Methods *****/
+
+/*
+ Decodes the key bindings of the preferences.
+
+ @return The action bound to each key AND an error describing the first key
+         that is not a single character or is bound to an unknown action.
+*/
+func (c Config) KeyBindings() (map[rune]Action, error) {
+	bindings := make(map[rune]Action)
+	for key, name := range c.Keys {
+		if utf8.RuneCountInString(key) != 1 {
+			return nil, fmt.Errorf("key %q must be a single character", key)
+		}
+		action, ok := keyActionNames[name]
+		if !ok {
+			return nil, fmt.Errorf("key %q is bound to unknown action %q", key, name)
+		}
+		r, _ := utf8.DecodeRuneInString(key)
+		bindings[r] = action
+	}
+	return bindings, nil
+}
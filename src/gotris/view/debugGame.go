@@ -1,21 +1,42 @@
+//go:build !js || !wasm
+// +build !js !wasm
+
 /*
  * File:        debugGame.go
  *
  * Author:      Schuyler Martin <schuylermartin45@gmail.com>
  *
- * Description: The most basic gameplay mode, requiring no additional
- *              dependencies. Movement is slow and the display looks awful.
+ * Description: The most basic gameplay mode, requiring no rendering
+ *              dependencies. The only dependency is used to read single
+ *              keypresses. Movement is slow and the display looks awful.
  */
 package view
 
 import (
-	"../model"
 	"bufio"
 	"fmt"
+	"github.com/schuylermartin45/gotris/src/gotris/model"
+	"golang.org/x/term"
 	"os"
 	"strings"
+	"time"
 )
 
+/***** Constants *****/
+
+// Line ending used when printing. Raw terminals do not return the cursor to the
+// start of the line on a newline.
+const eol string = "\r\n"
+
+/***** Variables *****/
+
+// Default characters the board is drawn with. Every cell is drawn as the digits
+// of its color code, so empty cells are `00`.
+var debugTheme = Theme{
+	Filled: [2]rune{ColorDigit, ColorDigit},
+	Empty:  [2]rune{'0', '0'},
+}
+
 /***** Types *****/
 
 // KeyMap Maps keyboard input to actions.
@@ -27,6 +48,14 @@ type DebugGame struct {
 	board *model.Board
 	// Input buffer
 	reader *bufio.Reader
+	// Keypresses from the user. Input is read in the background so that gravity
+	// is not stalled while waiting on the user.
+	input chan string
+	// Original state of the terminal, restored on exit. Nil if the terminal
+	// was never put into raw mode.
+	termState *term.State
+	// Characters the board is drawn with. Unset uses the debug theme.
+	theme *Theme
 }
 
 /***** Functions *****/
@@ -40,19 +69,16 @@ Retrieves an action from a string.
 is returned.
 */
 func getAction(action string) Action {
-	action = strings.ToLower(strings.TrimSuffix(action, "\n"))
+	action = strings.ToLower(action)
 	var keyMap KeyMap = map[string]Action{
-		"a":      ActionLeft,
-		"left":   ActionLeft,
-		"d":      ActionRight,
-		"right":  ActionRight,
-		"s":      ActionDown,
-		"down":   ActionDown,
-		"w":      ActionRotate,
-		"rotate": ActionRotate,
-		" ":      ActionFastDown,
-		"e":      ActionExit,
-		"exit":   ActionExit,
+		"a": ActionLeft,
+		"d": ActionRight,
+		"s": ActionDown,
+		"w": ActionRotate,
+		" ": ActionFastDown,
+		"e": ActionExit,
+		// Raw mode swallows the interrupt signal
+		"\x03": ActionExit,
 	}
 	if value, ok := keyMap[action]; ok {
 		return value
@@ -67,39 +93,73 @@ func (d DebugGame) RenderHelpMenu() string {
 	return "Debug Mode\n" +
 		"\nAbout\n" +
 		"  This mode is a basic text-mode written for debugging the game.\n" +
-		"  It is written only using standard Go packages.\n" +
+		"  It is written using standard Go packages and `x/term`.\n" +
 		"\nControls\n" +
-		"  * W:       Rotate\n" +
-		"  * A:       Move left\n" +
-		"  * S:       Move right\n" +
-		"  * D:       Move down\n" +
-		"  * [Space]: Drop tile to floor\n" +
-		"  * E:       Exit game\n"
+		"  * W:          Rotate\n" +
+		"  * A:          Move left\n" +
+		"  * S:          Move right\n" +
+		"  * D:          Move down\n" +
+		"  * [Space]:    Drop tile to floor\n" +
+		"  * E/[Ctrl-C]: Exit game\n"
 }
 
 // InitGame initializes the game.
 func (d *DebugGame) InitGame(b *model.Board) {
 	d.board = b
-	d.reader = bufio.NewReader(os.Stdin)
+	// If you cleared a row, play the terminal bell for fun
+	d.board.OnEvent(func(event model.Event) {
+		if event == model.EventLinesCleared {
+			fmt.Print("\a")
+		}
+	})
+	// Start reading input on the first game. Subsequent games share the reader.
+	if d.input == nil {
+		// Raw mode delivers keypresses immediately, without waiting on enter.
+		// Input that isn't a terminal (i.e. a pipe) is read as-is.
+		fd := int(os.Stdin.Fd())
+		if term.IsTerminal(fd) {
+			state, err := term.MakeRaw(fd)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(ERROR_SCREEN_INIT)
+			}
+			d.termState = state
+		}
+		d.reader = bufio.NewReader(os.Stdin)
+		d.input = make(chan string)
+		go d.readInput()
+	}
 }
 
 // RenderGame runs the primary gameplay loop.
 func (d *DebugGame) RenderGame() bool {
+	lastTick := time.Now()
 	for {
-		// Advance the game
-		_, endGame := d.board.Next()
+		// Advance the game by however much time has passed
+		now := time.Now()
+		_, endGame := d.board.Tick(now.Sub(lastTick))
+		lastTick = now
 
 		// Draw the board
-		fmt.Printf("Score:  %8v\n", d.board.GetDisplayScore())
-		fmt.Println("----------------")
+		fmt.Printf("Score:  %8v"+eol, d.board.GetDisplayScore())
+		fmt.Print("----------------" + eol)
 		d.drawItem()
 
-		// Handle user input
+		// Handle user input, unless gravity kicks in first. A closed input
+		// stream ends the game.
 		fmt.Print("Next move (w/a/s/d/ /e): ")
-		keypress, _ := d.reader.ReadString('\n')
-		ActionHandler(d.board, getAction(keypress), func() {
-			endGame = true
-		})
+		select {
+		case keypress, ok := <-d.input:
+			if !ok {
+				endGame = true
+				break
+			}
+			ActionHandler(d.board, getAction(keypress), func() {
+				endGame = true
+			})
+		case <-time.After(d.board.GetGravityInterval()):
+		}
+		fmt.Print(eol)
 
 		// Stop the loop on the event that the game has ended.
 		if endGame {
@@ -107,18 +167,44 @@ func (d *DebugGame) RenderGame() bool {
 		}
 	}
 	fmt.Print("Play again? (y/n): ")
-	playAgain, _ := d.reader.ReadString('\n')
-	playAgain = strings.ToLower(strings.TrimSuffix(playAgain, "\n"))
-	return (playAgain == "y") || (playAgain == "yes")
+	playAgain := strings.ToLower(<-d.input)
+	fmt.Print(eol)
+	return playAgain == "y"
+}
+
+/*
+ Sets the characters blocks and empty cells on the board are drawn with.
+
+ @param theme Characters to draw the board with.
+*/
+func (d *DebugGame) SetTheme(theme Theme) {
+	d.theme = &theme
 }
 
 // ExitGame is a callback triggered when the game terminates
 func (d *DebugGame) ExitGame() {
-	// Intentionally left blank
+	// Give the user their terminal back
+	if d.termState != nil {
+		term.Restore(int(os.Stdin.Fd()), d.termState)
+	}
 }
 
 /** Internal **/
 
+/*
+ Reads keypresses in the background, until the input stream closes.
+*/
+func (d *DebugGame) readInput() {
+	for {
+		key, _, err := d.reader.ReadRune()
+		if err != nil {
+			close(d.input)
+			return
+		}
+		d.input <- string(key)
+	}
+}
+
 /*
  Dumps a tile or board to a string for printing.
 
@@ -126,16 +212,20 @@ func (d *DebugGame) ExitGame() {
 */
 func (d DebugGame) drawItem() {
 	view := ""
+	theme := debugTheme
+	if d.theme != nil {
+		theme = *d.theme
+	}
 	d.board.RenderBoard(func(row uint8, col uint8, isEOL bool, color model.TileColor) {
 		// The original Tetris used 2 text characters to represent 1 unit of
 		// width. After rendering each bit as 1 text character, this made a lot
 		// of sense, as the the width and height now visually closer to a 1:1
 		// proportion (as opposed to being closer to 1:2).
-		view += string(rune('0' + color))
-		view += string(rune('0' + color))
+		cell := theme.Cell(color)
+		view += string(cell[:])
 		// Add a newline after the last character in the row
 		if isEOL {
-			view += "\n"
+			view += eol
 		}
 	})
 	fmt.Print(view)
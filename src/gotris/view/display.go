@@ -8,7 +8,7 @@
 package view
 
 import (
-	"../model"
+	"github.com/schuylermartin45/gotris/src/gotris/model"
 )
 
 /***** Constants *****/
@@ -18,22 +18,30 @@ const (
 	EXIT_SUCCESS      = 0
 	ERROR_USAGE       = 1
 	ERROR_SCREEN_INIT = 2
+	ERROR_SERVE       = 3
+	ERROR_BENCH       = 4
 )
 
+// Number of actions that can be queued up before input has to wait on the game
+// loop to catch up
+const actionBufferSize = 16
+
 /***** Types *****/
 
-// Action describes a user-caused event in the game.
-type Action uint8
+// Action describes a user-caused event in the game. Actions live in the model,
+// so that the board can perform them.
+type Action = model.Action
 
 // Enumeration of actions
 const (
-	ActionIllegal  Action = 0
-	ActionLeft     Action = 1
-	ActionRight    Action = 2
-	ActionDown     Action = 3
-	ActionFastDown Action = 4
-	ActionRotate   Action = 5
-	ActionExit     Action = 6
+	ActionIllegal    = model.ActionIllegal
+	ActionLeft       = model.ActionLeft
+	ActionRight      = model.ActionRight
+	ActionDown       = model.ActionDown
+	ActionFastDown   = model.ActionFastDown
+	ActionRotate     = model.ActionRotate
+	ActionExit       = model.ActionExit
+	ActionScreenshot = model.ActionScreenshot
 )
 
 // ExitFunc is a callback triggered on `ActionExit`. This breaks the game loop
@@ -65,17 +73,9 @@ func ActionHandler(board *model.Board, action Action, onExit ExitFunc) {
 	switch action {
 	case ActionIllegal:
 		return
-	case ActionLeft:
-		board.MoveLeft()
-	case ActionRight:
-		board.MoveRight()
-	case ActionDown:
-		board.MoveDown()
-	case ActionFastDown:
-		board.MoveFastDown()
-	case ActionRotate:
-		board.Rotate()
 	case ActionExit:
 		onExit()
+	default:
+		board.Apply(action)
 	}
 }
@@ -0,0 +1,143 @@
+//go:build !js || !wasm
+// +build !js !wasm
+
+/*
+ * File:        menu.go
+ *
+ * Author:      Schuyler Martin <schuylermartin45@gmail.com>
+ *
+ * Description: Title screen of the text mode, where the player picks how to
+ *              play before the first game.
+ */
+package view
+
+import (
+	"fmt"
+	"time"
+)
+
+/***** Constants *****/
+
+// Selects the highlighted menu item. Like mouse drops, only the text mode has
+// menus, so this action is not one of the model's.
+const actionSelect Action = 253
+
+// Items on the title screen, from top to bottom
+const (
+	menuPlay = iota
+	menuGameMode
+	menuLevel
+	menuHighScores
+	menuQuit
+	menuItems
+)
+
+/***** Types *****/
+
+// MenuChoice holds what the player picked on the title screen.
+type MenuChoice struct {
+	// Rules to play by. One of `GameModeNames`.
+	GameMode string
+	// Level to start at
+	Level uint8
+}
+
+/***** Methods *****/
+
+/*
+ Shows the title screen. The arrow keys pick an item and change its setting,
+ Enter selects it. If the title screen sits idle for the attract timeout, the
+ computer plays a demo game until a key is pressed. The title screen takes the
+ place of the prompt before the first game.
+
+ @param choice Settings the menu starts on.
+
+ @return The settings the player picked AND true if the player picked play.
+         False if the player quit.
+*/
+func (t *TextGame) RunMenu(choice MenuChoice) (MenuChoice, bool) {
+	t.initScreen()
+	t.attractShown = true
+	selected := menuPlay
+	gameMode := 0
+	for i, name := range GameModeNames {
+		if name == choice.GameMode {
+			gameMode = i
+		}
+	}
+	for {
+		choice.GameMode = GameModeNames[gameMode]
+		t.drawMenu(selected, choice)
+		// Never idle out if the demo is disabled
+		var idle <-chan time.Time
+		if t.attractTimeout > 0 {
+			idle = time.After(t.attractTimeout)
+		}
+		var action Action
+		select {
+		case action = <-t.actions:
+		case <-idle:
+			t.playDemo()
+			continue
+		}
+		switch action {
+		case ActionExit:
+			return choice, false
+		case ActionRotate:
+			selected = (selected + menuItems - 1) % menuItems
+		case ActionDown:
+			selected = (selected + 1) % menuItems
+		case ActionLeft:
+			if selected == menuGameMode {
+				gameMode = (gameMode + len(GameModeNames) - 1) % len(GameModeNames)
+			} else if (selected == menuLevel) && (choice.Level > 0) {
+				choice.Level--
+			}
+		case ActionRight:
+			if selected == menuGameMode {
+				gameMode = (gameMode + 1) % len(GameModeNames)
+			} else if (selected == menuLevel) && (choice.Level < 255) {
+				choice.Level++
+			}
+		case actionSelect, ActionFastDown:
+			switch selected {
+			case menuPlay:
+				return choice, true
+			case menuHighScores:
+				// Any key goes back to the menu
+				t.drawHighScores(-1)
+				if <-t.actions == ActionExit {
+					return choice, false
+				}
+			case menuQuit:
+				return choice, false
+			}
+		}
+	}
+}
+
+/*
+ Draws the title screen.
+
+ @param selected Menu item that is highlighted.
+ @param choice   Settings picked so far.
+*/
+func (t *TextGame) drawMenu(selected int, choice MenuChoice) {
+	t.screen.Fill(' ', lookupColor(BoardBackground))
+	labels := [menuItems]string{
+		menuPlay:       "Play",
+		menuGameMode:   fmt.Sprintf("Mode:  < %s >", choice.GameMode),
+		menuLevel:      fmt.Sprintf("Level: < %d >", choice.Level),
+		menuHighScores: "High Scores",
+		menuQuit:       "Quit",
+	}
+	t.drawStrCentered(-6, "G O T R I S")
+	for item, label := range labels {
+		if item == selected {
+			label = "> " + label + " <"
+		}
+		t.drawStrCentered((2*item)-3, label)
+	}
+	t.drawStrCentered(7, "Arrows to pick, Enter to select")
+	t.screen.Show()
+}
@@ -0,0 +1,103 @@
+//go:build !js || !wasm
+// +build !js !wasm
+
+/*
+ * File:        scoreScreen.go
+ *
+ * Author:      Schuyler Martin <schuylermartin45@gmail.com>
+ *
+ * Description: High score table of the text mode, shown from the title screen
+ *              and after a game that makes the table.
+ */
+package view
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+/***** Constants *****/
+
+// How long the high score table is shown after a game that made the table
+const scoreScreenDuration = 5 * time.Second
+
+// Most characters of the player's initials kept in the high score table
+const maxInitials = 3
+
+/***** Methods *****/
+
+/*
+ Sets the high score table games are recorded in. Without one, games are not
+ recorded.
+
+ @param scores   High score table to record games in.
+ @param path     File the table is saved to after a game makes it. Empty to not
+                 save the table.
+ @param initials Initials of the player, recorded with their games.
+*/
+func (t *TextGame) SetScoreBoard(scores *ScoreBoard, path string, initials string) {
+	t.scores = scores
+	t.scoresPath = path
+	if len(initials) > maxInitials {
+		initials = initials[:maxInitials]
+	}
+	t.initials = strings.ToUpper(initials)
+}
+
+/*
+ Records the game that just ended in the high score table. If the game made the
+ table, the table is saved and shown with the game highlighted. Pressing any key
+ skips the table.
+*/
+func (t *TextGame) recordScore() {
+	if (t.scores == nil) || (t.board.GetScore() == 0) {
+		return
+	}
+	rank, ok := t.scores.Add(ScoreEntry{
+		Initials: t.initials,
+		Score:    t.board.GetScore(),
+		Lines:    t.board.GetLines(),
+		Date:     time.Now(),
+	})
+	if !ok {
+		return
+	}
+	// The table still shows if it can't be saved, the game just won't be there
+	// next time.
+	if t.scoresPath != "" {
+		SaveScoreBoard(t.scoresPath, *t.scores)
+	}
+	t.drawHighScores(rank)
+	t.wait(scoreScreenDuration)
+}
+
+/*
+ Draws the high score table.
+
+ @param highlight Rank of the game to highlight. -1 to not highlight any game.
+*/
+func (t *TextGame) drawHighScores(highlight int) {
+	t.screen.Fill(' ', lookupColor(BoardBackground))
+	t.drawStrCentered(-7, "H I G H   S C O R E S")
+	var entries []ScoreEntry
+	if t.scores != nil {
+		entries = t.scores.Entries
+	}
+	if len(entries) == 0 {
+		t.drawStrCentered(0, "No games played yet")
+	}
+	for rank, entry := range entries {
+		// Every line is the same width, so the columns line up when centered
+		line := fmt.Sprintf("%2d. %-3s  %06d00  %4d lines  %s", rank+1, entry.Initials,
+			entry.Score, entry.Lines, entry.Date.Format("2006-01-02"))
+		if rank == highlight {
+			line = "> " + line + " <"
+		} else {
+			line = "  " + line + "  "
+		}
+		t.drawStrCentered(rank-5, line)
+	}
+	t.drawStrCentered(7, "Press any key")
+	t.screen.Show()
+}
@@ -0,0 +1,126 @@
+/*
+ * File:        scores.go
+ *
+ * Author:      Schuyler Martin <schuylermartin45@gmail.com>
+ *
+ * Description: Table of the best games played, saved between launches.
+ */
+package view
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+/***** Constants *****/
+
+// Number of games kept in the high score table
+const maxScoreEntries = 10
+
+/***** Types *****/
+
+// ScoreEntry is a game in the high score table.
+type ScoreEntry struct {
+	// Initials of the player
+	Initials string `json:"initials"`
+	// Raw score of the game, as kept by the board
+	Score uint32 `json:"score"`
+	// Rows cleared in the game
+	Lines uint16 `json:"lines"`
+	// When the game was played
+	Date time.Time `json:"date"`
+}
+
+// ScoreBoard holds the best games played, best first.
+type ScoreBoard struct {
+	Entries []ScoreEntry `json:"entries"`
+}
+
+/***** Functions *****/
+
+/*
+ Get the default location of the high score table, next to the preferences file.
+
+ @return The path of the high score table AND an error if the user has no
+         configuration directory.
+*/
+func ScoreBoardPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gotris", "scores.json"), nil
+}
+
+/*
+ Loads the high score table from a file. A missing file is not an error, as no
+ games have been played yet.
+
+ @param path File to load.
+
+ @return The high score table, empty if the file is missing, AND an error if the
+         file could not be read or is invalid.
+*/
+func LoadScoreBoard(path string) (ScoreBoard, error) {
+	var scores ScoreBoard
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return scores, nil
+	} else if err != nil {
+		return scores, err
+	}
+	if err := json.Unmarshal(data, &scores); err != nil {
+		return scores, fmt.Errorf("%s: %v", path, err)
+	}
+	return scores, nil
+}
+
+/*
+ Saves the high score table to a file, creating its directory if needed.
+
+ @param path   File to save to.
+ @param scores High score table to save.
+
+ @return An error if the file could not be written.
+*/
+func SaveScoreBoard(path string, scores ScoreBoard) error {
+	data, err := json.MarshalIndent(scores, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+/***** Methods *****/
+
+/*
+ Adds a game to the high score table, if it is good enough. Ties go to the game
+ played first.
+
+ @param entry Game to add.
+
+ @return The game's rank in the table (0 is the best) AND true if it made the
+         table. False if the table is full of better games.
+*/
+func (s *ScoreBoard) Add(entry ScoreEntry) (int, bool) {
+	rank := sort.Search(len(s.Entries), func(i int) bool {
+		return s.Entries[i].Score < entry.Score
+	})
+	if rank >= maxScoreEntries {
+		return 0, false
+	}
+	s.Entries = append(s.Entries, ScoreEntry{})
+	copy(s.Entries[rank+1:], s.Entries[rank:])
+	s.Entries[rank] = entry
+	if len(s.Entries) > maxScoreEntries {
+		s.Entries = s.Entries[:maxScoreEntries]
+	}
+	return rank, true
+}
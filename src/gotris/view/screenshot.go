@@ -0,0 +1,76 @@
+/*
+ * File:        screenshot.go
+ *
+ * Author:      Schuyler Martin <schuylermartin45@gmail.com>
+ *
+ * Description: Saves still images of the board, for players to show off their
+ *              proudest stacks.
+ */
+package view
+
+import (
+	"github.com/schuylermartin45/gotris/src/gotris/model"
+	"image"
+	imgcolor "image/color"
+	"image/draw"
+	"image/png"
+	"os"
+)
+
+/***** Constants *****/
+
+// Size of a block in a screenshot, in pixels. Each block is surrounded by a one
+// pixel gap, so blocks are distinguishable from one another.
+const (
+	screenshotBlockSize = 16
+	screenshotBlockGap  = 1
+)
+
+/***** Functions *****/
+
+/*
+ Converts a tile color to an opaque image color.
+
+ @param color Tile color to convert.
+
+ @return The image color of the tile color.
+*/
+func screenshotColor(color model.TileColor) imgcolor.RGBA {
+	r, g, b := color.RGB()
+	return imgcolor.RGBA{R: r, G: g, B: b, A: 0xFF}
+}
+
+/*
+ Saves a PNG image of the board, as it is currently displayed.
+
+ @param b    Board to take a picture of.
+ @param path File to save the image to.
+
+ @return An error if the image could not be saved.
+*/
+func Screenshot(b *model.Board, path string) error {
+	const cellSize = screenshotBlockSize + (2 * screenshotBlockGap)
+	img := image.NewRGBA(image.Rect(0, 0, int(model.BoardWidth)*cellSize, int(model.BoardHeight)*cellSize))
+	// The gaps between blocks take on the empty cell color
+	draw.Draw(img, img.Bounds(), image.NewUniform(screenshotColor(model.Transparent)), image.Point{}, draw.Src)
+	b.RenderBoard(func(row uint8, col uint8, isEOL bool, color model.TileColor) {
+		x := (int(col) * cellSize) + screenshotBlockGap
+		y := (int(row) * cellSize) + screenshotBlockGap
+		rgba := screenshotColor(color)
+		for dy := 0; dy < screenshotBlockSize; dy++ {
+			for dx := 0; dx < screenshotBlockSize; dx++ {
+				img.SetRGBA(x+dx, y+dy, rgba)
+			}
+		}
+	})
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err = png.Encode(file, img); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -1,3 +1,6 @@
+//go:build !js || !wasm
+// +build !js !wasm
+
 /*
  * File:        textGame.go
  *
@@ -8,21 +11,167 @@
 package view
 
 import (
-	"../model"
 	"fmt"
 	"github.com/gdamore/tcell"
+	"github.com/schuylermartin45/gotris/src/gotris/model"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 )
 
+/***** Constants *****/
+
+// How long a banner message stays on screen
+const bannerDuration = 2 * time.Second
+
+// How long the points scored stay on screen
+const popupDuration = 1 * time.Second
+
+// Time between frames drawn during play (about 30 frames per second)
+const frameInterval = time.Second / 30
+
+// How long the board flashes for after a Tetris
+const flashDuration = 100 * time.Millisecond
+
+// Default length of the "get ready" countdown before a game starts
+const defaultCountdown = 3 * time.Second
+
+// Default time the player can be idle on startup before a demo game starts
+const DefaultAttractTimeout = 5 * time.Second
+
+// Prompt shown over the demo game
+const attractPrompt = "Press any key to play"
+
+// How long it takes to fill the board when the game is over
+const gameOverFillTime = 1 * time.Second
+
+// Holding down soft drops the tile at a fixed rate. Terminals do not report key
+// releases, so the key is considered held while presses keep repeating within
+// the hold window.
+const (
+	softDropRate = 30 * time.Millisecond
+	softDropHold = 150 * time.Millisecond
+)
+
+// Number of levels each color scheme lasts for, with color progression enabled
+const levelsPerScheme = 3
+
+// Default glyphs drawn for blocks and empty cells
+const (
+	defaultBlockGlyph = '▇'
+	defaultEmptyGlyph = '.'
+)
+
+// Drops the tile in a clicked column. Only the text mode has a mouse, so these
+// actions are not the model's. The column is added to the action, so every click
+// that is queued up keeps its own column.
+const actionMouseDrop Action = 128
+
+// Shows or hides the placement hint. Like mouse drops, only the text mode has it.
+const actionToggleHint Action = 254
+
+// Turns the next tile preview, to see how the tile will look rotated. Like mouse
+// drops, only the text mode has it.
+const actionRotatePreview Action = 252
+
+// Enters or exits the zone, when the zone is enabled
+const actionToggleZone Action = 251
+
+// Glyph drawn for the trail a hard drop would leave
+const dropPathGlyph = '░'
+
+// Glyph drawn where the placement hint suggests putting the tile
+const hintGlyph = '▒'
+
+// Size of the panel with the score and next tile, in characters. The panel runs
+// from the score down to the zone status.
+const (
+	panelWidth  = 2 + len("Score:  00000000")
+	panelHeight = int(model.TileSize) + 7
+)
+
+// Glyph drawn on the left side of empty cells when grid lines are enabled. Lined
+// up with the empty glyph on the right side, this makes a dotted grid.
+const gridGlyph = '┊'
+
 /***** Types *****/
 
 // TextGame renders Gotris in an interactive text-based UI.
 type TextGame struct {
 	board  *model.Board
 	screen tcell.Screen
+	// Short message flashed on screen and the time it disappears
+	banner      string
+	bannerUntil time.Time
+	// Points scored, shown over the board near where they were scored
+	popup      string
+	popupRow   uint8
+	popupUntil time.Time
+	// Time the board stops flashing, after a Tetris
+	flashUntil time.Time
+	// Turns off the terminal bell and flashing, for players that find them
+	// annoying
+	noEffects bool
+	// Length of the countdown shown before gameplay starts
+	countdown time.Duration
+	// Idle time on startup before the computer plays a demo game, and whether
+	// startup has already been handled
+	attractTimeout time.Duration
+	attractShown   bool
+	// Queues up keys pressed as actions. The game loop applies them in the order
+	// they were pressed, so the board is only ever changed by one goroutine.
+	actions chan Action
+	// Hides the next tile preview, for an extra challenge
+	hidePreview bool
+	// Where the next tile and score are drawn, relative to the board
+	previewPosition PreviewPosition
+	// Hides blocks once they are placed, for an even bigger challenge
+	hidden bool
+	// Tracks if the down key is being held and when it was last pressed
+	softDropHeld bool
+	lastSoftDrop time.Time
+	// Changes the color scheme every few levels, starting from the selected one
+	colorProgression bool
+	scheme           int
+	// Characters blocks and empty cells on the board are drawn with
+	theme Theme
+	// Draws the height of every column beside the board
+	heatmap bool
+	// Draws the path the dropping tile would take on a hard drop
+	dropPath bool
+	// Draws where the computer player would put the dropping tile, for practice
+	hint bool
+	// Draws every block twice as wide and tall, for big terminals
+	big bool
+	// Number of times the next tile preview is turned, and the next tile the
+	// turns apply to. The tile itself always spawns unturned.
+	previewTurns uint8
+	previewTile  model.Tile
+	// Lets the player enter the zone, where cleared rows are banked for a bonus
+	zone bool
+	// Extra keys picked by the player, checked before the default keys
+	keyBindings map[rune]Action
+	// Lets the mouse control the tile
+	mouse bool
+	// High score table games are recorded in, the file it is saved to, and the
+	// initials games are recorded under
+	scores     *ScoreBoard
+	scoresPath string
+	initials   string
 }
 
+// PreviewPosition is where the panel with the next tile and score is drawn,
+// relative to the board.
+type PreviewPosition uint8
+
+// PreviewPosition enumerations
+const (
+	PreviewRight PreviewPosition = 0
+	PreviewLeft  PreviewPosition = 1
+	PreviewBelow PreviewPosition = 2
+)
+
 // Text Mode Color Enum
 type color uint8
 
@@ -41,8 +190,82 @@ const (
 	TextColor       color = 10
 )
 
+// colorScheme is a palette of tile styles, indexed by the text mode tile colors
+// (`Blue` through `Red`).
+type colorScheme [Red + 1]tcell.Style
+
+/***** Variables *****/
+
+// Default characters the board is drawn with
+var textTheme = Theme{
+	Filled: [2]rune{defaultBlockGlyph, defaultBlockGlyph},
+	Empty:  [2]rune{' ', defaultEmptyGlyph},
+}
+
+// Names of the built-in color schemes, in the same order as the schemes
+var colorSchemeNames = []string{"classic", "neon", "pastel"}
+
+// Built-in color schemes. The first scheme is the classic palette. The rest are
+// cycled through as the level increases, if color progression is enabled.
+var colorSchemes = []colorScheme{
+	// Classic
+	{
+		Blue:   lookupColor(Blue),
+		Cyan:   lookupColor(Cyan),
+		Grey:   lookupColor(Grey),
+		Yellow: lookupColor(Yellow),
+		Green:  lookupColor(Green),
+		Violet: lookupColor(Violet),
+		Red:    lookupColor(Red),
+	},
+	// Neon
+	{
+		Blue:   tcell.StyleDefault.Foreground(tcell.ColorAqua).Background(tcell.ColorTeal),
+		Cyan:   tcell.StyleDefault.Foreground(tcell.ColorFuchsia).Background(tcell.ColorPurple),
+		Grey:   tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorSilver),
+		Yellow: tcell.StyleDefault.Foreground(tcell.ColorLime).Background(tcell.ColorGreen),
+		Green:  tcell.StyleDefault.Foreground(tcell.ColorOrange).Background(tcell.ColorDarkOrange),
+		Violet: tcell.StyleDefault.Foreground(tcell.ColorPink).Background(tcell.ColorDeepPink),
+		Red:    tcell.StyleDefault.Foreground(tcell.ColorGold).Background(tcell.ColorOlive),
+	},
+	// Pastel
+	{
+		Blue:   tcell.StyleDefault.Foreground(tcell.ColorPowderBlue).Background(tcell.ColorSteelBlue),
+		Cyan:   tcell.StyleDefault.Foreground(tcell.ColorLavender).Background(tcell.ColorMediumPurple),
+		Grey:   tcell.StyleDefault.Foreground(tcell.ColorSilver).Background(tcell.ColorSlateGray),
+		Yellow: tcell.StyleDefault.Foreground(tcell.ColorKhaki).Background(tcell.ColorTan),
+		Green:  tcell.StyleDefault.Foreground(tcell.ColorPaleGreen).Background(tcell.ColorSeaGreen),
+		Violet: tcell.StyleDefault.Foreground(tcell.ColorThistle).Background(tcell.ColorLightPink),
+		Red:    tcell.StyleDefault.Foreground(tcell.ColorLightCoral).Background(tcell.ColorIndianRed),
+	},
+}
+
 /***** Functions *****/
 
+/*
+ Get the names of the built-in color schemes.
+
+ @return Names of the color schemes that can be selected.
+*/
+func ColorSchemeNames() []string {
+	return append([]string{}, colorSchemeNames...)
+}
+
+/*
+ Constructs a text-mode game.
+
+ @return A text-mode game with default settings.
+*/
+func NewTextGame() *TextGame {
+	t := new(TextGame)
+	t.countdown = defaultCountdown
+	t.attractTimeout = DefaultAttractTimeout
+	t.actions = make(chan Action, actionBufferSize)
+	t.theme = textTheme
+	t.keyBindings = make(map[rune]Action)
+	return t
+}
+
 // lookupColor returns the `tcell` color code for a given color
 func lookupColor(clr color) tcell.Style {
 	bkgrd := tcell.ColorBlack
@@ -76,29 +299,123 @@ func lookupColor(clr color) tcell.Style {
 	}
 }
 
-// lookupTileColor maps TileColor to the `tcell` color code
-func lookupTileColor(clr model.TileColor) tcell.Style {
+// lookupTileColor maps TileColor to the `tcell` color code in a color scheme
+func lookupTileColor(clr model.TileColor, scheme colorScheme) tcell.Style {
 	switch clr {
 	case model.Transparent:
 		return lookupColor(BoardForeground)
 	case model.Blue:
-		return lookupColor(Blue)
+		return scheme[Blue]
 	case model.Cyan:
-		return lookupColor(Cyan)
+		return scheme[Cyan]
 	case model.Grey:
-		return lookupColor(Grey)
+		return scheme[Grey]
 	case model.Yellow:
-		return lookupColor(Yellow)
+		return scheme[Yellow]
 	case model.Green:
-		return lookupColor(Green)
+		return scheme[Green]
 	case model.Violet:
-		return lookupColor(Violet)
+		return scheme[Violet]
 	case model.Red:
-		return lookupColor(Red)
+		return scheme[Red]
 	}
 	return 0x00
 }
 
+/*
+ Calculates where the panel with the next tile and score is drawn.
+
+ @param pos    Where the panel goes.
+ @param scale  Number of characters tall each block is drawn.
+ @param boardW Width of the board, in blocks.
+ @param boardH Height of the board, in blocks.
+
+ @return The x and y offsets of the panel's top-left corner from the board's.
+*/
+func panelOffset(pos PreviewPosition, scale int, boardW int, boardH int) (int, int) {
+	switch pos {
+	case PreviewLeft:
+		return -boardW - panelWidth, 0
+	case PreviewBelow:
+		return 0, (scale * boardH) + 1
+	}
+	return (2 * scale * boardW) + boardW, 0
+}
+
+/*
+ Calculates where the heatmap is drawn. The heatmap goes to the left of the
+ board, unless the panel is there.
+
+ @param pos    Where the panel with the next tile and score goes.
+ @param scale  Number of characters tall each block is drawn.
+ @param boardW Width of the board, in blocks.
+
+ @return The x offset of the heatmap from the board.
+*/
+func heatmapOffset(pos PreviewPosition, scale int, boardW int) int {
+	if pos == PreviewLeft {
+		return (2 * scale * boardW) + 3
+	}
+	return -boardW - 4
+}
+
+/*
+ Calculates where the board is drawn, relative to the center of the screen. The
+ board is drawn on the opposite side of the center from the panel, so the panel
+ gets the other half of the screen.
+
+ @param pos    Where the panel with the next tile and score goes.
+ @param scale  Number of characters tall each block is drawn.
+ @param boardW Width of the board, in blocks.
+
+ @return The x offset of the board from the center of the screen.
+*/
+func boardOffset(pos PreviewPosition, scale int, boardW int) int {
+	if pos == PreviewLeft {
+		return 0
+	}
+	return -2 * scale * boardW
+}
+
+/*
+ Calculates the smallest terminal the board fits in, with the panel and heatmap
+ around it.
+
+ @param heatmap True if the heatmap is drawn.
+ @param scale   Number of characters tall each block is drawn.
+ @param pos     Where the panel with the next tile and score goes.
+
+ @return The minimum width and height of the terminal, in characters.
+*/
+func minScreenSize(heatmap bool, scale int, pos PreviewPosition) (int, int) {
+	boardW, boardH := int(model.BoardWidth), int(model.BoardHeight)
+	boardX := boardOffset(pos, scale, boardW)
+	// Furthest anything is drawn to the left and right of the center
+	left, right := 0, 0
+	extend := func(x int, width int) {
+		if -(boardX + x) > left {
+			left = -(boardX + x)
+		}
+		if (boardX + x + width) > right {
+			right = boardX + x + width
+		}
+	}
+	extend(0, 2*scale*boardW)
+	panelX, panelY := panelOffset(pos, scale, boardW, boardH)
+	extend(panelX, panelWidth)
+	if heatmap {
+		extend(heatmapOffset(pos, scale, boardW), boardW)
+	}
+	height := scale * boardH
+	if pos == PreviewBelow {
+		height = panelY + panelHeight
+	}
+	if right > left {
+		left = right
+	}
+	return 2 * left, height
+}
+
 /***** Methods *****/
 
 // RenderHelpMenu returns a string to display the help menu in the terminal.
@@ -113,14 +430,232 @@ func (t TextGame) RenderHelpMenu() string {
 		"  * S/[Down]:       Move right\n" +
 		"  * D/[Right]:      Move down\n" +
 		"  * [Space]:        Drop tile to floor\n" +
+		"  * R:              Turn the next tile preview\n" +
+		"  * H:              Show or hide the placement hint\n" +
+		"  * Z:              Enter or leave the zone (with `--zone`)\n" +
+		"  * [F12]:          Save a screenshot\n" +
 		"  * [Esc]/[Ctrl-C]: Exit game\n"
 }
 
+/*
+ Sets whether the next tile is previewed next to the board. The next tile is
+ still picked as usual when hidden.
+
+ @param show True to show the next tile (the default), false to hide it.
+*/
+func (t *TextGame) SetPreview(show bool) {
+	t.hidePreview = !show
+}
+
+/*
+ Sets where the next tile and score are drawn, for terminals where the default
+ doesn't fit well.
+
+ @param pos Where to draw the next tile and score, relative to the board.
+            Defaults to `PreviewRight`.
+*/
+func (t *TextGame) SetPreviewPosition(pos PreviewPosition) {
+	t.previewPosition = pos
+}
+
+/*
+ Sets the character blocks are drawn with. Every block is drawn two characters
+ wide, so the glyph should be a single-width character.
+
+ @param glyph Character to draw blocks with. Defaults to '▇'.
+*/
+func (t *TextGame) SetBlockGlyph(glyph rune) {
+	t.theme.Filled = [2]rune{glyph, glyph}
+}
+
+/*
+ Sets the character empty cells on the board are drawn with. Like blocks, every
+ empty cell is two characters wide, with the glyph drawn on the right.
+
+ @param glyph Character to draw empty cells with. Defaults to '.'.
+*/
+func (t *TextGame) SetEmptyGlyph(glyph rune) {
+	t.theme.Empty[1] = glyph
+}
+
+/*
+ Sets the characters blocks and empty cells on the board are drawn with. This
+ replaces any glyphs or grid lines set before.
+
+ @param theme Characters to draw the board with.
+*/
+func (t *TextGame) SetTheme(theme Theme) {
+	t.theme = theme
+}
+
+/*
+ Sets how long the player can be idle on startup (or on the title screen) before
+ the computer plays a demo game. The demo plays until a key is pressed, which
+ starts the player's game (or goes back to the title screen).
+
+ @param timeout Idle time before the demo starts. 0 disables the demo, starting
+                the player's game right away.
+*/
+func (t *TextGame) SetAttractTimeout(timeout time.Duration) {
+	t.attractTimeout = timeout
+}
+
+/*
+ Sets whether grid lines are drawn between empty cells on the board. Blocks are
+ drawn over the grid.
+
+ @param enabled True to draw grid lines. False to leave them out (the default).
+*/
+func (t *TextGame) SetGridLines(enabled bool) {
+	t.theme.Empty[0] = ' '
+	if enabled {
+		t.theme.Empty[0] = gridGlyph
+	}
+}
+
+/*
+ Binds a key to an action. Bound keys take priority over the default keys, so
+ they can also replace a default key's action.
+
+ @param key    Character of the key.
+ @param action Action the key performs.
+*/
+func (t *TextGame) SetKeyBinding(key rune, action Action) {
+	t.keyBindings[key] = action
+}
+
+/*
+ Sets whether a hint is drawn where the computer player would put the dropping
+ tile, for players learning to stack. The hint can also be toggled during play
+ with the `h` key. The hint is never drawn with hidden blocks, as it would give
+ away the shape of the stack.
+
+ @param enabled True to draw the hint. False to leave it out (the default).
+*/
+func (t *TextGame) SetHint(enabled bool) {
+	t.hint = enabled
+}
+
+/*
+ Sets whether the player can enter the zone with the `z` key. In the zone, tiles
+ only fall when dropped and filled rows are banked at the bottom of the board.
+ Pressing `z` again clears every banked row at once, for a bonus.
+
+ @param enabled True to allow the zone. False to leave it out (the default).
+*/
+func (t *TextGame) SetZone(enabled bool) {
+	t.zone = enabled
+}
+
+/*
+ Sets whether blocks are drawn at twice the size, each block taking up 2x2 cells
+ of the normal size. This only changes how the board is drawn; the board is
+ still 10x20 blocks.
+
+ @param enabled True to draw big blocks. False to draw blocks at the normal size
+                (the default).
+*/
+func (t *TextGame) SetBigBlocks(enabled bool) {
+	t.big = enabled
+}
+
+/*
+ Sets whether the mouse controls the tile. Clicking a column on the board drops
+ the tile in it and scrolling rotates the tile. Must be set before the game is
+ initialized.
+
+ @param enabled True to use the mouse. False to ignore it (the default), so
+                stray clicks don't drop tiles.
+*/
+func (t *TextGame) SetMouse(enabled bool) {
+	t.mouse = enabled
+}
+
+/*
+ Sets whether a faint trail is drawn along the path the dropping tile would take
+ on a hard drop. The trail is never drawn with hidden blocks, as it would give
+ away the shape of the stack.
+
+ @param enabled True to draw the trail. False to leave it out (the default).
+*/
+func (t *TextGame) SetDropPath(enabled bool) {
+	t.dropPath = enabled
+}
+
+/*
+ Sets whether effects are played. With effects, the terminal bell rings when
+ rows are cleared and the board flashes on a Tetris.
+
+ @param enabled True to play effects (the default). False to turn them off.
+*/
+func (t *TextGame) SetEffects(enabled bool) {
+	t.noEffects = !enabled
+}
+
+/*
+ Sets whether a gauge of the stack's height in every column is drawn beside the
+ board, warning the player where the stack is getting tall.
+
+ @param enabled True to draw the gauge. False to leave it out (the default).
+*/
+func (t *TextGame) SetHeatmap(enabled bool) {
+	t.heatmap = enabled
+}
+
+/*
+ Selects the color scheme tiles are drawn with. With color progression enabled,
+ this is the scheme the progression starts from.
+
+ @param name Name of a built-in color scheme.
+
+ @return True if the scheme was selected. False if no scheme has the name.
+*/
+func (t *TextGame) SetColorScheme(name string) bool {
+	for i, schemeName := range colorSchemeNames {
+		if schemeName == name {
+			t.scheme = i
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ Sets whether the tile colors change every few levels, cycling through the
+ built-in color schemes.
+
+ @param enabled True to cycle color schemes. False to keep the classic colors
+                (the default).
+*/
+func (t *TextGame) SetColorProgression(enabled bool) {
+	t.colorProgression = enabled
+}
+
+/*
+ Sets whether placed blocks are hidden. The dropping tile is always visible, so
+ the player has to remember the rest of the stack.
+
+ @param hidden True to hide placed blocks. False to show them (the default).
+*/
+func (t *TextGame) SetHidden(hidden bool) {
+	t.hidden = hidden
+}
+
 // InitGame initializes the game.
 func (t *TextGame) InitGame(b *model.Board) {
 	t.board = b
+	t.board.OnEvent(t.handleEvent)
+	t.bannerUntil = time.Time{}
+	t.popupUntil = time.Time{}
+	t.flashUntil = time.Time{}
+	t.initScreen()
+}
 
-	// Init the screen on first game. Subsequent games do not re-initialized.
+/*
+ Initializes the screen and starts listening for input. Only the first call does
+ anything, so the screen is set up once for every game (and the title screen).
+*/
+func (t *TextGame) initScreen() {
 	if t.screen == nil {
 		tcell.SetEncodingFallback(tcell.EncodingFallbackASCII)
 		var error error
@@ -133,25 +668,91 @@ func (t *TextGame) InitGame(b *model.Board) {
 			fmt.Fprintf(os.Stderr, "%v\n", error)
 			os.Exit(ERROR_SCREEN_INIT)
 		}
+		if t.mouse {
+			t.screen.EnableMouse()
+		}
 		// Kick off event listener thread.
 		go t.initEventListener()
+		// Being killed exits the game the same way the exit key does, so the
+		// terminal gets restored.
+		go t.initSignalListener()
 	}
 }
 
 // RenderGame runs the primary gameplay loop.
 func (t *TextGame) RenderGame() bool {
-	// Primary game loop loops until the game completes
+	defer t.restoreOnPanic()
+
+	// Wait for the player on startup. If they take too long, the computer plays
+	// until they are ready.
+	if !t.attractShown && (t.attractTimeout > 0) {
+		t.attractShown = true
+		t.runAttract()
+	}
+
+	// Give the player a moment to get ready. The board is not ticked during the
+	// countdown, so the first tile will not start falling until it completes.
+	t.drawBoard()
+	for remaining := t.countdown; remaining > 0; {
+		// Show whole seconds, rounding up any fraction of a second
+		secs := (remaining + time.Second - 1) / time.Second
+		t.drawStrCentered(0, fmt.Sprintf("Ready?...%d", secs))
+		t.screen.Show()
+		step := remaining % time.Second
+		if step == 0 {
+			step = time.Second
+		}
+		time.Sleep(step)
+		remaining -= step
+	}
+
+	// Ignore keys pressed during the countdown
+	t.discardActions()
+
+	// Primary game loop loops until the game completes. Keys are applied as
+	// soon as they are pressed, while the game advances and is redrawn on a
+	// steady frame tick, independent of gravity.
+	frames := time.NewTicker(frameInterval)
+	defer frames.Stop()
+	startTime := time.Now()
+	lastTick := startTime
+	softDropElapsed := time.Duration(0)
+	t.drawBoard()
 	for {
-		// Advance the game
-		_, endGame := t.board.Next()
-		t.drawBoard()
+		select {
+		case action := <-t.actions:
+			// Only exiting works while the game is paused
+			if t.screenFits() || (action == ActionExit) {
+				t.applyAction(action)
+			}
+			continue
+		case <-frames.C:
+		}
 
-		// Draw the game. Game speed increases with level until a certain point.
-		delay := 500 - int(50*t.board.GetLevel())
-		if delay < 100 {
-			delay = 100
+		// Advance the game by however much time has passed. Gravity is handled
+		// by the model. Held down keys soft drop at a fixed rate.
+		now := time.Now()
+		dt := now.Sub(lastTick)
+		lastTick = now
+		// The game is paused while the terminal is too small to show it
+		if !t.screenFits() {
+			t.drawBoard()
+			continue
 		}
-		time.Sleep(time.Duration(delay) * time.Millisecond)
+		if t.isSoftDropping() {
+			softDropElapsed += dt
+			for softDropElapsed >= softDropRate {
+				t.board.Apply(ActionDown)
+				softDropElapsed -= softDropRate
+			}
+		} else {
+			softDropElapsed = 0
+		}
+		_, endGame := t.board.Tick(dt)
+		if delta := t.board.LastScoreDelta(); delta > 0 {
+			t.showScorePopup(delta)
+		}
+		t.drawBoard()
 
 		// Stop the loop on the event that the game has ended.
 		if endGame {
@@ -159,15 +760,17 @@ func (t *TextGame) RenderGame() bool {
 		}
 	}
 
-	// Count-down to play again
-	replayX, replayY := t.screen.Size()
-	replayY /= 2
+	t.drawGameOver(time.Since(startTime))
+	t.recordScore()
+
+	// Count-down to play again. Any key, other than exiting (which is handled
+	// while waiting), starts the next game right away.
 	for i := 10; i > 0; i-- {
-		displayStr := fmt.Sprintf("Playing again?...%02d (Esc to exit)", i)
-		newReplayX := (replayX / 2) - (len(displayStr) / 2)
-		t.drawStr(newReplayX, replayY, displayStr)
+		t.drawStrCentered(0, fmt.Sprintf("Playing again?...%02d (Esc to exit)", i))
 		t.screen.Show()
-		time.Sleep(time.Duration(1) * time.Second)
+		if _, pressed := t.wait(time.Second); pressed {
+			break
+		}
 	}
 	return true
 }
@@ -178,6 +781,102 @@ func (t *TextGame) ExitGame() {
 	t.screen.Fini()
 }
 
+/*
+ Reacts to events reported by the board.
+
+ @param event Event that occurred.
+*/
+func (t *TextGame) handleEvent(event model.Event) {
+	switch event {
+	case model.EventLevelUp:
+		t.showBanner(fmt.Sprintf("LEVEL %d", t.board.GetLevel()))
+	case model.EventPerfectClear:
+		t.showBanner("PERFECT CLEAR")
+	case model.EventLinesCleared:
+		// If you cleared a row, play the terminal bell for fun
+		if !t.noEffects {
+			t.screen.Beep()
+		}
+	case model.EventTetris:
+		if !t.noEffects {
+			t.flashUntil = time.Now().Add(flashDuration)
+		}
+	}
+}
+
+/*
+ Flashes a short message next to the board.
+
+ @param msg Message to display.
+*/
+func (t *TextGame) showBanner(msg string) {
+	t.banner = msg
+	t.bannerUntil = time.Now().Add(bannerDuration)
+}
+
+/*
+ Prompts the player to start playing. If no key is pressed before the attract
+ timeout, the computer plays demo games until a key is pressed.
+*/
+func (t *TextGame) runAttract() {
+	t.drawBoard()
+	t.drawStrCentered(0, attractPrompt)
+	t.screen.Show()
+	if _, pressed := t.wait(t.attractTimeout); pressed {
+		return
+	}
+	t.playDemo()
+}
+
+/*
+ Has the computer play demo games until a key is pressed.
+*/
+func (t *TextGame) playDemo() {
+	// Demo games are played on their own board. The player's board is left
+	// untouched for when they are ready.
+	player := t.board
+	defer func() {
+		t.board = player
+	}()
+	t.board = model.NewBoard()
+	lastTick := time.Now()
+	for {
+		now := time.Now()
+		if _, endGame := t.board.Tick(now.Sub(lastTick)); endGame {
+			t.board = model.NewBoard()
+		}
+		lastTick = now
+		// Place every tile as soon as it spawns
+		if t.board.HardDropDistance() > 0 {
+			if placement, ok := t.board.BestPlacement(); ok {
+				t.board.ApplyPlacement(placement)
+			}
+		}
+		t.drawBoard()
+		t.drawStrCentered(0, attractPrompt)
+		t.screen.Show()
+		if _, pressed := t.wait(t.board.GetGravityInterval()); pressed {
+			return
+		}
+	}
+}
+
+/*
+ Pops up the points scored over the board, next to the tile that scored them.
+
+ @param delta Base score gained.
+*/
+func (t *TextGame) showScorePopup(delta uint32) {
+	t.popup = fmt.Sprintf("+%d00", delta)
+	t.popupUntil = time.Now().Add(popupDuration)
+	t.popupRow = model.BoardHeight - 1
+	for _, cell := range t.board.LastLockedCells() {
+		if cell.Row < t.popupRow {
+			t.popupRow = cell.Row
+		}
+	}
+}
+
 /*
  Draws a string.
 
@@ -199,6 +898,239 @@ func (t *TextGame) drawStr(x int, y int, str string) {
 	}
 }
 
+/*
+ Draws a string, horizontally centered on the screen.
+
+ @param yOffset Rows above (negative) or below (positive) the center of the
+                screen to draw the string
+ @param str     String to draw
+*/
+func (t *TextGame) drawStrCentered(yOffset int, str string) {
+	sizeX, sizeY := t.screen.Size()
+	t.drawStr((sizeX/2)-(len(str)/2), (sizeY/2)+yOffset, str)
+}
+
+/*
+ Waits for some time, stopping early if a key is pressed. If the key pressed
+ exits the game, the game exits right away.
+
+ @param duration Maximum time to wait
+
+ @return The action of the key pressed (`ActionIllegal` for unmapped keys) AND
+         true if a key was pressed.
+*/
+func (t *TextGame) wait(duration time.Duration) (Action, bool) {
+	select {
+	case action := <-t.actions:
+		if action == ActionExit {
+			t.exitGame()
+		}
+		return action, true
+	case <-time.After(duration):
+		return ActionIllegal, false
+	}
+}
+
+/*
+ Applies an action to the board. The board is redrawn on the next frame.
+
+ @param action Action to apply.
+*/
+func (t *TextGame) applyAction(action Action) {
+	// A held down key is soft dropped by the game loop. Enter only works in
+	// menus.
+	if (action == ActionIllegal) || (action == actionSelect) ||
+		((action == ActionDown) && t.holdSoftDrop()) {
+		return
+	}
+	// Screenshots are taken by the view, the board has no part in them
+	if action == ActionScreenshot {
+		t.saveScreenshot()
+	} else if action == actionToggleHint {
+		t.hint = !t.hint
+	} else if action == actionRotatePreview {
+		t.previewTurns = (t.previewTurns + 1) % 4
+	} else if action == actionToggleZone {
+		// Releasing the zone scores outside of the game loop, so the points
+		// are shown right away
+		if t.zone && t.board.InZone() {
+			if t.board.ExitZone() > 0 {
+				t.showScorePopup(t.board.LastScoreDelta())
+			}
+		} else if t.zone {
+			t.board.EnterZone()
+		}
+	} else if (action >= actionMouseDrop) && (action < (actionMouseDrop + Action(model.BoardWidth))) {
+		t.board.DropInColumn(uint8(action - actionMouseDrop))
+	} else {
+		ActionHandler(t.board, action, t.exitGame)
+	}
+}
+
+/*
+ Throws away every action that is waiting in the queue, without blocking.
+ Exiting is still honored.
+*/
+func (t *TextGame) discardActions() {
+	for {
+		select {
+		case action := <-t.actions:
+			if action == ActionExit {
+				t.exitGame()
+			}
+		default:
+			return
+		}
+	}
+}
+
+/*
+ Saves a screenshot of the board to the current directory, letting the player
+ know how it went.
+*/
+func (t *TextGame) saveScreenshot() {
+	path := "gotris-" + time.Now().Format("20060102-150405") + ".png"
+	if err := Screenshot(t.board, path); err != nil {
+		t.showBanner("SCREENSHOT FAILED")
+		return
+	}
+	t.showBanner("SAVED " + path)
+}
+
+/*
+ Restores the terminal and exits the program. Only the game loop may call this,
+ so the screen is never torn down while it is being drawn to.
+*/
+func (t *TextGame) exitGame() {
+	t.screen.Fini()
+	os.Exit(EXIT_SUCCESS)
+}
+
+/*
+ Restores the terminal if the current goroutine is panicking, then carries on
+ panicking. Must be deferred to work.
+*/
+func (t *TextGame) restoreOnPanic() {
+	if r := recover(); r != nil {
+		t.screen.Fini()
+		panic(r)
+	}
+}
+
+/*
+ Tracks presses of the down key, to detect when the key is held.
+
+ @return True if the key is being held. False if it was only pressed once.
+*/
+func (t *TextGame) holdSoftDrop() bool {
+	now := time.Now()
+	t.softDropHeld = now.Sub(t.lastSoftDrop) < softDropHold
+	t.lastSoftDrop = now
+	return t.softDropHeld
+}
+
+/*
+ Checks if the down key is still held.
+
+ @return True if the tile should be soft dropped.
+*/
+func (t *TextGame) isSoftDropping() bool {
+	return t.softDropHeld && (time.Since(t.lastSoftDrop) < softDropHold)
+}
+
+/*
+ Determines the color scheme to draw tiles with.
+
+ @return The selected color scheme, or the scheme for the current level if
+         color progression is enabled.
+*/
+func (t *TextGame) colorScheme() colorScheme {
+	if !t.colorProgression {
+		return colorSchemes[t.scheme]
+	}
+	return colorSchemes[(t.scheme+(int(t.board.GetLevel())/levelsPerScheme))%len(colorSchemes)]
+}
+
+/*
+ Calculates where the board is drawn on the screen.
+
+ @return The x and y screen coordinates of the board's top-left corner.
+*/
+func (t *TextGame) boardOrigin() (int, int) {
+	screenW, screenH := t.screen.Size()
+	scale := t.cellScale()
+	// The panel below the board is centered along with it
+	height := scale * int(model.BoardHeight)
+	if t.previewPosition == PreviewBelow {
+		_, panelY := panelOffset(t.previewPosition, scale, int(model.BoardWidth), int(model.BoardHeight))
+		height = panelY + panelHeight
+	}
+	return (screenW / 2) + boardOffset(t.previewPosition, scale, int(model.BoardWidth)),
+		(screenH / 2) - (height / 2)
+}
+
+/*
+ Get how many characters tall each block is drawn. Blocks are drawn twice as
+ wide as they are tall, so they look square.
+
+ @return The number of rows of characters per block.
+*/
+func (t *TextGame) cellScale() int {
+	if t.big {
+		return 2
+	}
+	return 1
+}
+
+/*
+ Checks if the terminal is big enough to show the whole board.
+
+ @return True if the board fits. False if parts of it would be cut off.
+*/
+func (t *TextGame) screenFits() bool {
+	screenW, screenH := t.screen.Size()
+	minW, minH := minScreenSize(t.heatmap, t.cellScale(), t.previewPosition)
+	return (screenW >= minW) && (screenH >= minH)
+}
+
+/*
+ Plays the game over sequence. The board fills with blocks from the bottom up,
+ then a summary of the game is shown. Pressing any key skips the animation.
+
+ @param playTime How long the game lasted
+*/
+func (t *TextGame) drawGameOver(playTime time.Duration) {
+	// Ignore keys pressed before the game ended
+	t.discardActions()
+
+	boardX, boardY := t.boardOrigin()
+	scale := t.cellScale()
+	boardW, boardH := int(t.board.Width()), int(t.board.Height())
+	step := gameOverFillTime / time.Duration(scale*boardH)
+	for row := (scale * boardH) - 1; row >= 0; row-- {
+		for col := 0; col < (2 * scale * boardW); col++ {
+			t.screen.SetContent(boardX+col, boardY+row, t.theme.Cell(model.Grey)[col%2], nil, lookupColor(Grey))
+		}
+		t.screen.Show()
+		if _, pressed := t.wait(step); pressed {
+			break
+		}
+	}
+
+	// Summarize the game above the replay prompt
+	summary := []string{
+		"GAME OVER",
+		"Score: " + t.board.GetDisplayScore(),
+		fmt.Sprintf("Level: %d", t.board.GetLevel()),
+		fmt.Sprintf("Lines: %d", t.board.GetLines()),
+		"Time:  " + playTime.Round(time.Second).String(),
+	}
+	for i, line := range summary {
+		t.drawStrCentered(i-len(summary)-1, line)
+	}
+	t.screen.Show()
+}
+
 /*
  Draws the current board to the screen.
 */
@@ -209,69 +1141,195 @@ func (t *TextGame) drawBoard() {
 		xToY = 2
 		yPad = xPad / xToY
 	)
-	screenW, screenH := t.screen.Size()
+	// Ask for a bigger terminal instead of drawing a clipped board
+	if !t.screenFits() {
+		minW, minH := minScreenSize(t.heatmap, t.cellScale(), t.previewPosition)
+		t.screen.Fill(' ', lookupColor(BoardBackground))
+		t.drawStrCentered(0, fmt.Sprintf("Please enlarge your terminal (need %dx%d)", minW, minH))
+		t.screen.Show()
+		return
+	}
+	// Starting coordinates for the board
+	boardX, boardY := t.boardOrigin()
+	scale := t.cellScale()
+	boardW, boardH := int(t.board.Width()), int(t.board.Height())
+	panelX, panelY := panelOffset(t.previewPosition, scale, boardW, boardH)
 	var (
-		// Starting coordinates for the board
-		boardX = (screenW / 2) - (int(model.BoardWidth) * 2)
-		boardY = (screenH / 2) - (int(model.BoardHeight) / 2)
 		// Starting coordinates for the next tile preview (relative to the board)
-		previewX = boardX + (xToY * int(model.BoardWidth)) + int(model.BoardWidth)
-		previewY = boardY + yPad
+		previewX = boardX + panelX
+		previewY = boardY + panelY + yPad
 		// Starting coordinates for the score (relative to the board)
 		scoreX = previewX + (xPad / 2)
-		scoreY = boardY
+		scoreY = boardY + panelY
 	)
 	t.screen.Fill(' ', lookupColor(BoardBackground))
+	scheme := t.colorScheme()
 
 	// Draw the main board
-	y := boardY
-	t.board.RenderBoard(func(row uint8, col uint8, isEOL bool, color model.TileColor) {
-		// Calculate the left and right block x coordinates
-		xL := boardX + (2 * int(col))
-		xR := boardX + (2 * int(col)) + 1
-		textColor := lookupTileColor(color)
-		if color != model.Transparent {
-			t.screen.SetContent(xL, y, '▇', nil, textColor)
-			t.screen.SetContent(xR, y, '▇', nil, textColor)
-		} else {
-			t.screen.SetContent(xL, y, ' ', nil, textColor)
-			t.screen.SetContent(xR, y, '.', nil, textColor)
+	renderBoard := t.board.RenderBoard
+	if t.hidden {
+		renderBoard = t.board.RenderBoardHidden
+	}
+	// Flash the cells of the tile that just locked. After a Tetris, the whole
+	// board flashes instead.
+	lockedCells := make(map[model.Cell]bool)
+	for _, cell := range t.board.LastLockedCells() {
+		lockedCells[cell] = true
+	}
+	flashing := time.Now().Before(t.flashUntil)
+	// Rows waiting to be cleared are wiped away from left to right
+	clearingRows := make(map[uint8]bool)
+	for _, row := range t.board.ClearingRows() {
+		clearingRows[row] = true
+	}
+	wipedCols := uint8(t.board.ClearProgress() * float64(boardW))
+	// Trail the dropping tile would leave on a hard drop
+	dropPath := make(map[model.Cell]bool)
+	tile, _, dropping := t.board.GetActiveTile()
+	if t.dropPath && !t.hidden && dropping {
+		for _, cell := range t.board.DropPathCells() {
+			dropPath[cell] = true
+		}
+	}
+	// Where the computer player would put the dropping tile
+	hint := make(map[model.Cell]bool)
+	if t.hint && !t.hidden && dropping {
+		for _, cell := range t.board.HintCells() {
+			hint[cell] = true
 		}
-		if isEOL {
-			y++
+	}
+	renderBoard(func(row uint8, col uint8, isEOL bool, color model.TileColor) {
+		textColor := lookupTileColor(color, scheme)
+		highlight := lockedCells[model.Cell{Row: row, Col: col}] || clearingRows[row]
+		if clearingRows[row] && (col < wipedCols) {
+			color = model.Transparent
+			textColor = lookupTileColor(color, scheme)
+			highlight = false
+		}
+		if highlight != flashing {
+			textColor = textColor.Reverse(true)
+		}
+		cell := t.theme.Cell(color)
+		empty := color == model.Transparent
+		if empty && hint[model.Cell{Row: row, Col: col}] {
+			cell = [2]rune{hintGlyph, hintGlyph}
+			textColor = lookupTileColor(tile.GetColor(), scheme)
+		} else if empty && dropPath[model.Cell{Row: row, Col: col}] {
+			cell = [2]rune{dropPathGlyph, dropPathGlyph}
+			textColor = lookupTileColor(tile.GetColor(), scheme).Dim(true)
+		}
+		// Big blocks repeat the cell across a square of characters
+		for dy := 0; dy < scale; dy++ {
+			y := boardY + (scale * int(row)) + dy
+			for dx := 0; dx < scale; dx++ {
+				// Calculate the left and right block x coordinates
+				xL := boardX + (2 * ((scale * int(col)) + dx))
+				t.screen.SetContent(xL, y, cell[0], nil, textColor)
+				t.screen.SetContent(xL+1, y, cell[1], nil, textColor)
+			}
 		}
 	})
 
+	// Draw the column height gauge to the left of the board, lined up with the
+	// board's rows. Columns turn from green to yellow to red as they fill up.
+	if t.heatmap {
+		gaugeX := boardX + heatmapOffset(t.previewPosition, scale, boardW)
+		for col, height := range t.board.Heatmap() {
+			style := lookupColor(Green)
+			if int(height) > (boardH * 3 / 4) {
+				style = lookupColor(Red)
+			} else if int(height) > (boardH / 2) {
+				style = lookupColor(Yellow)
+			}
+			for y := scale * (boardH - int(height)); y < (scale * boardH); y++ {
+				t.screen.SetContent(gaugeX+col, boardY+y, '█', nil, style)
+			}
+		}
+	}
+
+	// Draw the garbage waiting to rise along the right of the board, so the
+	// player knows how many rows to clear to cancel it
+	if pending := t.board.PendingGarbage(); pending > 0 {
+		gaugeX := boardX + (xToY * scale * boardW) + 1
+		for y := scale * (boardH - int(pending)); y < (scale * boardH); y++ {
+			t.screen.SetContent(gaugeX, boardY+y, '█', nil, lookupColor(Red))
+		}
+	}
+
 	// Draw the score
 	t.drawStr(scoreX, scoreY, "Score:  "+t.board.GetDisplayScore())
 
-	// Draw the next tile
-	y = previewY
-	t.board.RenderNextTile(func(row uint8, col uint8, isEOL bool, color model.TileColor) {
-		// Calculate the left and right block x coordinates
-		xL := previewX + (2 * int(col))
-		xR := previewX + (2 * int(col)) + 1
-		textColor := lookupTileColor(color)
-		if color != model.Transparent {
-			t.screen.SetContent(xL, y, '▇', nil, textColor)
-			t.screen.SetContent(xR, y, '▇', nil, textColor)
-		} else {
-			t.screen.SetContent(xL, y, ' ', nil, textColor)
-			t.screen.SetContent(xR, y, ' ', nil, textColor)
+	// Draw the next tile, unless it is hidden
+	y := previewY
+	if !t.hidePreview {
+		// Turns only last until the next tile changes
+		next := t.board.GetNextTile()
+		if !next.Equals(t.previewTile) {
+			t.previewTile = next
+			t.previewTurns = 0
 		}
-		if isEOL {
-			y++
+		// Until the first tile is picked, there is nothing to turn
+		preview := next
+		if _, _, height, _ := preview.BoundingBox(); height > 0 {
+			for turn := uint8(0); turn < t.previewTurns; turn++ {
+				preview.Rotate()
+			}
 		}
-	})
+		preview.Render(func(row uint8, col uint8, isEOL bool, color model.TileColor) {
+			// Calculate the left and right block x coordinates
+			xL := previewX + (2 * int(col))
+			xR := previewX + (2 * int(col)) + 1
+			textColor := lookupTileColor(color, scheme)
+			if color != model.Transparent {
+				cell := t.theme.Cell(color)
+				t.screen.SetContent(xL, y, cell[0], nil, textColor)
+				t.screen.SetContent(xR, y, cell[1], nil, textColor)
+			} else {
+				t.screen.SetContent(xL, y, ' ', nil, textColor)
+				t.screen.SetContent(xR, y, ' ', nil, textColor)
+			}
+			if isEOL {
+				y++
+			}
+		})
+	}
+
+	// Draw the points scored over the board, until they expire
+	if time.Now().Before(t.popupUntil) {
+		popupX := boardX + (((xToY * scale * boardW) - len(t.popup)) / 2)
+		t.drawStr(popupX, boardY+(scale*int(t.popupRow)), t.popup)
+	}
+
+	// Draw the banner under the next tile, until it expires
+	if time.Now().Before(t.bannerUntil) {
+		t.drawStr(scoreX, previewY+int(model.TileSize)+yPad, t.banner)
+	}
+
+	// Warn the player when the next row of garbage rises in survival mode
+	if survival, ok := t.board.GetGameMode().(*model.SurvivalMode); ok {
+		countdown := survival.GarbageCountdown().Round(time.Second)
+		t.drawStr(scoreX, previewY+int(model.TileSize)+(2*yPad), fmt.Sprintf("Garbage: %v", countdown))
+	}
+
+	// Show how many rows will be released when the zone ends
+	if t.board.InZone() {
+		t.drawStr(scoreX, previewY+int(model.TileSize)+(3*yPad), fmt.Sprintf("Zone:  %d rows", t.board.ZoneLines()))
+	}
 
 	// Render it all
 	t.screen.Show()
 }
 
 /*
- Initializes the event listener
+ Initializes the event listener. The listener runs in its own goroutine, so it
+ only polls for keys and queues them up. The board and screen are only touched by
+ the game loop.
 */
 func (t *TextGame) initEventListener() {
+	defer t.restoreOnPanic()
+
+	// Buttons held in the last mouse event, so a click is only reported once
+	var lastButtons tcell.ButtonMask
 	for {
 		event := t.screen.PollEvent()
 		switch eventType := event.(type) {
@@ -280,6 +1338,10 @@ func (t *TextGame) initEventListener() {
 			switch eventType.Key() {
 			// ASCII keys have to be handled separately
 			case tcell.KeyRune:
+				if bound, ok := t.keyBindings[eventType.Rune()]; ok {
+					action = bound
+					break
+				}
 				switch eventType.Rune() {
 				case 'a':
 					action = ActionLeft
@@ -293,6 +1355,12 @@ func (t *TextGame) initEventListener() {
 					action = ActionRotate
 				case ' ':
 					action = ActionFastDown
+				case 'h':
+					action = actionToggleHint
+				case 'r':
+					action = actionRotatePreview
+				case 'z':
+					action = actionToggleZone
 				}
 			case tcell.KeyLeft:
 				action = ActionLeft
@@ -302,24 +1370,54 @@ func (t *TextGame) initEventListener() {
 				action = ActionDown
 			case tcell.KeyUp:
 				action = ActionRotate
+			case tcell.KeyF12:
+				action = ActionScreenshot
+			case tcell.KeyEnter:
+				action = actionSelect
 			// Exit
 			case tcell.KeyCtrlC:
 				fallthrough
 			case tcell.KeyEsc:
 				action = ActionExit
 			}
-			if action != ActionIllegal {
-				ActionHandler(t.board, action, func() {
-					t.screen.Fini()
-					os.Exit(EXIT_SUCCESS)
-					return
-				})
-				// Re-render the board on action to make visual feedback more
-				// apparent
-				t.drawBoard()
+			// Hand the action off to the game loop, including exiting. Unmapped
+			// keys are still reported, as any key can skip animations and
+			// prompts.
+			t.actions <- action
+		case *tcell.EventMouse:
+			buttons := eventType.Buttons()
+			pressed := buttons &^ lastButtons
+			lastButtons = buttons
+			// The wheel does not report being released, so every notch counts
+			if (buttons & (tcell.WheelUp | tcell.WheelDown)) != 0 {
+				t.actions <- ActionRotate
+			} else if (pressed & tcell.Button1) != 0 {
+				// Clicks outside of the board are ignored
+				x, y := eventType.Position()
+				boardX, boardY := t.boardOrigin()
+				scale := t.cellScale()
+				col := (x - boardX) / (2 * scale)
+				row := (y - boardY) / scale
+				if (x < boardX) || (col >= int(model.BoardWidth)) ||
+					(y < boardY) || (row >= int(model.BoardHeight)) {
+					continue
+				}
+				t.actions <- actionMouseDrop + Action(col)
 			}
 		default:
 			continue
 		}
 	}
 }
+
+/*
+ Initializes the signal listener. Interrupt and termination signals are queued up
+ as exit actions, so the game loop can restore the terminal before exiting.
+*/
+func (t *TextGame) initSignalListener() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	for range signals {
+		t.actions <- ActionExit
+	}
+}
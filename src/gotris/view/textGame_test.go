@@ -0,0 +1,121 @@
+//go:build !js || !wasm
+// +build !js !wasm
+
+/*
+ * File:        textGame_test.go
+ *
+ * Author:      Schuyler Martin <schuylermartin45@gmail.com>
+ *
+ * Description: Tests for the text terminal gameplay mode.
+ */
+package view
+
+import (
+	"github.com/gdamore/tcell"
+	"github.com/schuylermartin45/gotris/src/gotris/model"
+	"sync"
+	"testing"
+	"time"
+)
+
+/***** Tests *****/
+
+/*
+ Clicks queued up before the game loop gets to them drop tiles in the columns
+ that were clicked, not the column clicked last.
+*/
+func TestQueuedClicksKeepColumns(t *testing.T) {
+	game := newTestTextGame(t)
+	game.SetMouse(true)
+	board := model.NewBoard()
+	game.InitGame(board)
+	go game.initEventListener()
+
+	x, y := game.boardOrigin()
+	columns := []uint8{2, 6}
+	for _, col := range columns {
+		game.screen.(tcell.SimulationScreen).InjectMouse(x+(2*int(col)), y+5, tcell.Button1, 0)
+		game.screen.(tcell.SimulationScreen).InjectMouse(x+(2*int(col)), y+5, tcell.ButtonNone, 0)
+	}
+	for _, col := range columns {
+		if err := board.ForceSpawn(model.Red); err != nil {
+			t.Fatal(err)
+		}
+		board.Next()
+		game.applyAction(<-game.actions)
+		tile, _, _ := board.GetActiveTile()
+		if _, leftCol, _, _ := tile.BoundingBox(); leftCol != col {
+			t.Errorf("tile dropped in column %d, expected %d", leftCol, col)
+		}
+		// Lock the tile
+		board.Next()
+	}
+}
+
+/*
+ Keys pressed from several goroutines at once are all queued, none dropped,
+ while the game loop plays on. Run with `-race` to check that only the game loop
+ touches the board.
+*/
+func TestConcurrentActions(t *testing.T) {
+	const presses = 50
+	game := newTestTextGame(t)
+	board := model.NewBoard()
+	board.SetDelays(0, 0)
+	game.InitGame(board)
+	go game.initEventListener()
+
+	keys := []tcell.Key{tcell.KeyLeft, tcell.KeyRight, tcell.KeyUp}
+	var pressers sync.WaitGroup
+	for _, key := range keys {
+		pressers.Add(1)
+		go func(key tcell.Key) {
+			defer pressers.Done()
+			for i := 0; i < presses; i++ {
+				game.screen.(tcell.SimulationScreen).PostEventWait(tcell.NewEventKey(key, 0, tcell.ModNone))
+			}
+		}(key)
+	}
+
+	// Stand in for the game loop, applying actions between gravity ticks
+	counts := make(map[Action]int)
+	for i := 0; i < len(keys)*presses; i++ {
+		action := <-game.actions
+		counts[action]++
+		game.applyAction(action)
+		board.Tick(time.Millisecond)
+	}
+	pressers.Wait()
+	for _, action := range []Action{ActionLeft, ActionRight, ActionRotate} {
+		if counts[action] != presses {
+			t.Errorf("queued %d of action %d, expected %d", counts[action], action, presses)
+		}
+	}
+	select {
+	case action := <-game.actions:
+		t.Errorf("queued unexpected action %d", action)
+	default:
+	}
+}
+
+/***** Internal Functions *****/
+
+/*
+ Constructs a text game that draws to a simulated screen, so tests can run
+ without a terminal.
+
+ @param t Test the game is for.
+
+ @return The game.
+*/
+func newTestTextGame(t *testing.T) *TextGame {
+	t.Helper()
+	screen := tcell.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		t.Fatal(err)
+	}
+	screen.SetSize(80, 40)
+	game := NewTextGame()
+	game.screen = screen
+	return game
+}
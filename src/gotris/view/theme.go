@@ -0,0 +1,50 @@
+/*
+ * File:        theme.go
+ *
+ * Author:      Schuyler Martin <schuylermartin45@gmail.com>
+ *
+ * Description: Characters that text-based modes draw the board with.
+ */
+package view
+
+import (
+	"github.com/schuylermartin45/gotris/src/gotris/model"
+)
+
+/***** Constants *****/
+
+// ColorDigit stands in for the digit of a block's color code in a theme.
+const ColorDigit rune = -1
+
+/***** Types *****/
+
+// Theme describes the characters a text-based mode draws cells with. Every cell
+// is drawn two characters wide, so the board's proportions are closer to square.
+type Theme struct {
+	// Characters drawn for a cell with a block in it
+	Filled [2]rune
+	// Characters drawn for an empty cell
+	Empty [2]rune
+}
+
+/***** Methods *****/
+
+/*
+ Get the characters a cell is drawn with.
+
+ @param color Color of the block in the cell. `Transparent` for empty cells.
+
+ @return The left and right characters of the cell.
+*/
+func (theme Theme) Cell(color model.TileColor) [2]rune {
+	cell := theme.Filled
+	if color == model.Transparent {
+		cell = theme.Empty
+	}
+	for i, glyph := range cell {
+		if glyph == ColorDigit {
+			cell[i] = rune('0' + color)
+		}
+	}
+	return cell
+}
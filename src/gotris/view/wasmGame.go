@@ -0,0 +1,231 @@
+//go:build js && wasm
+// +build js,wasm
+
+/*
+ * File:        wasmGame.go
+ *
+ * Author:      Schuyler Martin <schuylermartin45@gmail.com>
+ *
+ * Description: A gameplay mode that runs in the browser, drawing to an HTML
+ *              canvas.
+ */
+package view
+
+import (
+	"fmt"
+	"github.com/schuylermartin45/gotris/src/gotris/model"
+	"syscall/js"
+	"time"
+)
+
+/***** Constants *****/
+
+// Size of a block on the canvas, in pixels
+const wasmBlockSize = 24
+
+// Height of the score bar above the board, in pixels
+const wasmScoreHeight = 32
+
+// ID of the canvas element the game is drawn on. If the page does not have one,
+// a canvas is added to the page.
+const wasmCanvasID = "gotris"
+
+/***** Types *****/
+
+// WASMGame renders Gotris in the browser.
+type WASMGame struct {
+	board *model.Board
+	// Canvas the game is drawn on and its 2D drawing context
+	canvas  js.Value
+	context js.Value
+	// Queues up keys pressed as actions. Key events arrive on the browser's
+	// event loop, so they are handed off to the game loop.
+	actions chan Action
+	// Key listener registered with the page, released on exit
+	onKeyDown js.Func
+}
+
+/***** Functions *****/
+
+/*
+ Constructs a browser game.
+
+ @return A new browser game.
+*/
+func NewWASMGame() *WASMGame {
+	w := new(WASMGame)
+	w.actions = make(chan Action, actionBufferSize)
+	return w
+}
+
+/*
+ Retrieves an action from a browser key name.
+
+ @param key Key name, as reported by a `KeyboardEvent`.
+
+ @return Action derived from the key. If not found, `ActionIllegal` is returned.
+*/
+func getWASMAction(key string) Action {
+	switch key {
+	case "a", "ArrowLeft":
+		return ActionLeft
+	case "d", "ArrowRight":
+		return ActionRight
+	case "s", "ArrowDown":
+		return ActionDown
+	case "w", "ArrowUp":
+		return ActionRotate
+	case " ":
+		return ActionFastDown
+	case "Escape":
+		return ActionExit
+	}
+	return ActionIllegal
+}
+
+/***** Methods *****/
+
+// RenderHelpMenu returns a string to display the help menu.
+func (w WASMGame) RenderHelpMenu() string {
+	return "Browser Mode\n" +
+		"\nAbout\n" +
+		"  This mode runs in the browser, using WebAssembly.\n" +
+		"\nControls\n" +
+		"  * W/[Up]:    Rotate\n" +
+		"  * A/[Left]:  Move left\n" +
+		"  * D/[Right]: Move right\n" +
+		"  * S/[Down]:  Move down\n" +
+		"  * [Space]:   Drop tile to floor\n" +
+		"  * [Esc]:     Exit game\n"
+}
+
+// InitGame initializes the game.
+func (w *WASMGame) InitGame(b *model.Board) {
+	w.board = b
+
+	// Init the canvas on first game. Subsequent games re-use it.
+	if w.canvas.IsUndefined() {
+		document := js.Global().Get("document")
+		w.canvas = document.Call("getElementById", wasmCanvasID)
+		if w.canvas.IsNull() {
+			w.canvas = document.Call("createElement", "canvas")
+			w.canvas.Set("id", wasmCanvasID)
+			document.Get("body").Call("appendChild", w.canvas)
+		}
+		w.canvas.Set("width", int(model.BoardWidth)*wasmBlockSize)
+		w.canvas.Set("height", (int(model.BoardHeight)*wasmBlockSize)+wasmScoreHeight)
+		w.context = w.canvas.Call("getContext", "2d")
+
+		w.onKeyDown = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			action := getWASMAction(args[0].Get("key").String())
+			if action != ActionIllegal {
+				// Keep the page from scrolling on arrow keys and space
+				args[0].Call("preventDefault")
+			}
+			// Never block the browser's event loop. Keys pressed while the game
+			// loop is behind are dropped.
+			select {
+			case w.actions <- action:
+			default:
+			}
+			return nil
+		})
+		document.Call("addEventListener", "keydown", w.onKeyDown)
+	}
+}
+
+// RenderGame runs the primary gameplay loop, returning true to play again.
+func (w *WASMGame) RenderGame() bool {
+	exit := false
+	onExit := func() {
+		exit = true
+	}
+
+	lastTick := time.Now()
+	for !exit {
+		// Advance the game by however much time has passed
+		now := time.Now()
+		_, endGame := w.board.Tick(now.Sub(lastTick))
+		lastTick = now
+		w.drawBoard()
+
+		// Stop the loop on the event that the game has ended.
+		if endGame {
+			break
+		}
+
+		// Handle user input, unless gravity kicks in first
+		select {
+		case action := <-w.actions:
+			ActionHandler(w.board, action, onExit)
+		case <-time.After(w.board.GetGravityInterval()):
+		}
+	}
+	if exit {
+		return false
+	}
+
+	// Any key, other than exiting, plays again
+	w.drawMessage("GAME OVER - Press any key")
+	return <-w.actions != ActionExit
+}
+
+// ExitGame is a callback triggered when the game terminates
+func (w *WASMGame) ExitGame() {
+	if w.canvas.IsUndefined() {
+		return
+	}
+	js.Global().Get("document").Call("removeEventListener", "keydown", w.onKeyDown)
+	w.onKeyDown.Release()
+	w.drawMessage("Thanks for playing!")
+}
+
+/** Internal **/
+
+/*
+ Draws the score and the board on the canvas.
+*/
+func (w *WASMGame) drawBoard() {
+	w.context.Set("fillStyle", cssColor(model.Transparent))
+	w.context.Call("fillRect", 0, 0, w.canvas.Get("width"), w.canvas.Get("height"))
+
+	w.context.Set("fillStyle", "white")
+	w.context.Set("font", "16px monospace")
+	w.context.Call("fillText", "Score: "+w.board.GetDisplayScore(), 4, wasmScoreHeight-10)
+
+	w.board.RenderBoard(func(row uint8, col uint8, isEOL bool, color model.TileColor) {
+		if color == model.Transparent {
+			return
+		}
+		x := int(col) * wasmBlockSize
+		y := wasmScoreHeight + (int(row) * wasmBlockSize)
+		w.context.Set("fillStyle", cssColor(color))
+		// Leave a 1 pixel gap so blocks are distinguishable from one another
+		w.context.Call("fillRect", x+1, y+1, wasmBlockSize-2, wasmBlockSize-2)
+	})
+}
+
+/*
+ Draws a message in the middle of the board.
+
+ @param msg Message to draw.
+*/
+func (w *WASMGame) drawMessage(msg string) {
+	w.context.Set("fillStyle", "white")
+	w.context.Set("font", "16px monospace")
+	w.context.Set("textAlign", "center")
+	w.context.Call("fillText", msg, w.canvas.Get("width").Int()/2, w.canvas.Get("height").Int()/2)
+	w.context.Set("textAlign", "start")
+}
+
+/*
+ Converts a tile color to a CSS color string.
+
+ @param color Color to convert.
+
+ @return The CSS representation of the color.
+*/
+func cssColor(color model.TileColor) string {
+	r, g, b := color.RGB()
+	return fmt.Sprintf("rgb(%d, %d, %d)", r, g, b)
+}